@@ -34,7 +34,8 @@ import (
 
 //=============================================================================
 
-const SlotNum int = 48*5
+//--- Days of slots before a trade that are returned as triggering sequence
+const SequenceDays int = 5
 
 //=============================================================================
 //===
@@ -52,6 +53,10 @@ type BacktestedConfig struct {
 	Sequences     []*TriggeringSequence `json:"sequences"`
 	Equity        *Equity               `json:"equity"`
 	ProfitDistrib *ProfitDistribution   `json:"profitDistrib"`
+	InSample      *SampleResult         `json:"inSample,omitempty"`
+	OutOfSample   *SampleResult         `json:"outOfSample,omitempty"`
+	MonteCarlo    *MonteCarloResult     `json:"monteCarlo,omitempty"`
+	Metrics       *PerformanceMetrics   `json:"metrics"`
 
 	//------------------------
 
@@ -59,13 +64,20 @@ type BacktestedConfig struct {
 	excludedSet   *ExcludedSet
 	brokerProduct *db.BrokerProduct
 	spec          *BiasBacktestSpec
+	fxConverter   *FxConverter
+	slotSize      int
+	atr           []float64
+	minutes       *minuteSource
+	costs         *costModel
+	calendar      *sessionCalendar
+	noSequences   bool
 }
 
 //=============================================================================
 //=== Constructor
 //=============================================================================
 
-func NewBacktestedConfig(bc *BiasConfig, bp *db.BrokerProduct, spec *BiasBacktestSpec) (*BacktestedConfig, error) {
+func NewBacktestedConfig(bc *BiasConfig, bp *db.BrokerProduct, spec *BiasBacktestSpec, slotSize int) (*BacktestedConfig, error) {
 	es, err := NewExcludedSet(bc.Excludes)
 
 	return &BacktestedConfig{
@@ -75,6 +87,8 @@ func NewBacktestedConfig(bc *BiasConfig, bp *db.BrokerProduct, spec *BiasBacktes
 		excludedSet  : es,
 		brokerProduct: bp,
 		spec         : spec,
+		slotSize     : slotSize,
+		costs        : newCostModel(spec.Costs, bp),
 	}, err
 }
 
@@ -83,10 +97,16 @@ func NewBacktestedConfig(bc *BiasConfig, bp *db.BrokerProduct, spec *BiasBacktes
 //=============================================================================
 
 func (btc *BacktestedConfig) RunBacktest(ti *TimeInfo, currDp *ds.DataPoint, prevDp *ds.DataPoint, i int, dataPoints []*ds.DataPoint) {
+	//--- The session could have closed in a gap of data, after the previous bar
+
+	if btc.currTrade != nil && btc.IsSessionClosedBefore(currDp, prevDp) {
+		btc.EndTrade(prevDp, ExitConditionEndOfSession)
+	}
+
 	if btc.currTrade == nil {
 		//--- Check if we can start a new trade
 
-		if btc.IsDayAllowed(ti) {
+		if btc.IsDayAllowed(ti) && btc.IsInsideSession(currDp) {
 			if btc.IsStartOfTrade(ti) {
 				btc.StartTrade(currDp, prevDp, i, dataPoints)
 			}
@@ -98,12 +118,24 @@ func (btc *BacktestedConfig) RunBacktest(ti *TimeInfo, currDp *ds.DataPoint, pre
 	if btc.currTrade != nil {
 		//--- Check if we need to exit from current trade
 
+		btc.currTrade.slots++
+
 		if btc.IsEndOfTrade(ti) {
 			btc.EndTrade(currDp, ExitConditionNormal)
+		} else if !btc.IsInsideSession(currDp) {
+			//--- Bars outside the session are not checked
+		} else if btc.ResolveIntrabar(currDp) {
+			//--- The trade was closed inside the slot
 		} else if btc.currTrade.IsInStopLoss(currDp) {
-			btc.EndTrade(currDp, ExitConditionStop)
+			btc.EndTrade(currDp, btc.currTrade.stopCondition)
 		} else if btc.currTrade.IsInProfit(currDp) {
 			btc.EndTrade(currDp, ExitConditionProfit)
+		} else if btc.IsMaxTimeReached() {
+			btc.EndTrade(currDp, ExitConditionMaxTime)
+		} else if btc.IsSessionClose(currDp) {
+			btc.EndTrade(currDp, ExitConditionEndOfSession)
+		} else {
+			btc.currTrade.UpdateStop(currDp)
 		}
 	}
 }
@@ -128,15 +160,80 @@ func (btc *BacktestedConfig) IsEndOfTrade(ti *TimeInfo) bool {
 
 //=============================================================================
 
+//--- Walks the 1m bars of a slot that touched the stop or the target. The stop
+//--- wins when both are touched in the same 1m bar
+
+func (btc *BacktestedConfig) ResolveIntrabar(currDp *ds.DataPoint) bool {
+	bt := btc.currTrade
+
+	if btc.minutes == nil || !btc.spec.IntrabarResolution {
+		return false
+	}
+
+	if !bt.IsInStopLoss(currDp) && !bt.IsInProfit(currDp) {
+		return false
+	}
+
+	for _, dp := range btc.minutes.getBars(getSlotTime(currDp, btc.slotSize), currDp.Time) {
+		if bt.IsInStopLoss(dp) {
+			btc.EndTrade(dp, bt.stopCondition)
+			return true
+		}
+
+		if bt.IsInProfit(dp) {
+			btc.EndTrade(dp, ExitConditionProfit)
+			return true
+		}
+	}
+
+	return false
+}
+
+//=============================================================================
+//--- Without a trading session, all bars are inside
+
+func (btc *BacktestedConfig) IsInsideSession(currDp *ds.DataPoint) bool {
+	return btc.calendar == nil || btc.calendar.isInside(getSlotTime(currDp, btc.slotSize))
+}
+
+//=============================================================================
+//--- Trades are closed at the end of the session only when asked to, otherwise
+//--- they are kept open until the next session
+
+func (btc *BacktestedConfig) IsSessionClose(currDp *ds.DataPoint) bool {
+	return btc.spec.ExitAtSessionEnd && btc.calendar != nil && btc.calendar.hasEndIn(getSlotTime(currDp, btc.slotSize), currDp.Time)
+}
+
+//=============================================================================
+
+func (btc *BacktestedConfig) IsSessionClosedBefore(currDp, prevDp *ds.DataPoint) bool {
+	return btc.spec.ExitAtSessionEnd && btc.calendar != nil && btc.calendar.hasEndIn(prevDp.Time, getSlotTime(currDp, btc.slotSize))
+}
+
+//=============================================================================
+
+func (btc *BacktestedConfig) IsMaxTimeReached() bool {
+	return btc.spec.MaxSlots > 0 && btc.currTrade.slots >= btc.spec.MaxSlots
+}
+
+//=============================================================================
+
 func (btc *BacktestedConfig) StartTrade(currDp, prevDp *ds.DataPoint, index int, dataPoints []*ds.DataPoint) {
-	btc.Sequences = append(btc.Sequences, NewTriggeringSequence(index, dataPoints, SlotNum))
-	btc.currTrade = NewBiasTrade(currDp, prevDp, btc)
+	if !btc.noSequences {
+		btc.Sequences = append(btc.Sequences, NewTriggeringSequence(index, dataPoints, getSlotsPerDay(btc.slotSize) * SequenceDays))
+	}
+
+	btc.currTrade = NewBiasTrade(currDp, prevDp, btc, index)
 }
 
 //=============================================================================
 
 func (btc *BacktestedConfig) EndTrade(currDp *ds.DataPoint, exitCondition int8) {
-	btc.currTrade.Close(currDp, btc.brokerProduct, exitCondition)
+	btc.currTrade.Close(currDp, btc.costs, exitCondition)
+
+	if btc.fxConverter != nil {
+		btc.currTrade.ConvertProfit(btc.fxConverter.RateAt(btc.currTrade.ExitTime))
+	}
 
 	btc.GrossProfit += btc.currTrade.GrossProfit
 	btc.NetProfit   += btc.currTrade.NetProfit
@@ -159,7 +256,54 @@ func (btc *BacktestedConfig) Finish() {
 	btc.NetProfit   = math.Trunc(btc.NetProfit)
 
 	btc.Equity        = NewEquity(btc.Trades)
-	btc.ProfitDistrib = NewProfitDistribution(btc.Trades, float64(btc.brokerProduct.CostPerOperation))
+	btc.ProfitDistrib = NewProfitDistribution(btc.Trades, btc.costs.commission)
+	btc.Metrics       = NewPerformanceMetrics(btc.Trades)
+
+	if btc.spec.oosFrom != nil {
+		var inSample, outOfSample []*BiasTrade
+
+		for _, bt := range btc.Trades {
+			if bt.EntryTime.Before(*btc.spec.oosFrom) {
+				inSample = append(inSample, bt)
+			} else {
+				outOfSample = append(outOfSample, bt)
+			}
+		}
+
+		btc.InSample    = NewSampleResult(inSample)
+		btc.OutOfSample = NewSampleResult(outOfSample)
+	}
+}
+
+//=============================================================================
+//--- Without 1m bars, the trade enters at the close of the previous slot
+
+func (btc *BacktestedConfig) getEntryValue(currDp, prevDp *ds.DataPoint) float64 {
+	if btc.minutes != nil && btc.spec.EntryAtOpen {
+		bars := btc.minutes.getBars(getSlotTime(currDp, btc.slotSize), currDp.Time)
+		if len(bars) > 0 {
+			return bars[0].Open
+		}
+	}
+
+	return prevDp.Close
+}
+
+//=============================================================================
+//--- The ATR distance uses the bar before the entry, the last one known when
+//--- the trade starts
+
+func (btc *BacktestedConfig) getTrailingDelta(index int) float64 {
+	switch btc.spec.TrailingType {
+		case TrailingTypeAtr:
+			if index > 0 && index <= len(btc.atr) && !math.IsNaN(btc.atr[index -1]) {
+				return btc.atr[index -1] * btc.spec.TrailingStop
+			}
+
+			return 0
+	}
+
+	return btc.spec.TrailingStop
 }
 
 //=============================================================================
@@ -206,6 +350,45 @@ func NewTriggeringSequence(index int, dataPoints []*ds.DataPoint, slotNum int) *
 	}
 }
 
+//=============================================================================
+//===
+//=== SampleResult
+//===
+//=============================================================================
+
+type SampleResult struct {
+	NumTrades     int     `json:"numTrades"`
+	GrossProfit   float64 `json:"grossProfit"`
+	NetProfit     float64 `json:"netProfit"`
+	GrossAvgTrade float64 `json:"grossAvgTrade"`
+	NetAvgTrade   float64 `json:"netAvgTrade"`
+	Equity        *Equity `json:"equity"`
+}
+
+//=============================================================================
+
+func NewSampleResult(trades []*BiasTrade) *SampleResult {
+	sr := &SampleResult{
+		NumTrades: len(trades),
+		Equity   : NewEquity(trades),
+	}
+
+	for _, bt := range trades {
+		sr.GrossProfit += bt.GrossProfit
+		sr.NetProfit   += bt.NetProfit
+	}
+
+	if sr.NumTrades > 0 {
+		sr.GrossAvgTrade = core.Trunc2d(sr.GrossProfit / float64(sr.NumTrades))
+		sr.NetAvgTrade   = core.Trunc2d(sr.NetProfit   / float64(sr.NumTrades))
+	}
+
+	sr.GrossProfit = math.Trunc(sr.GrossProfit)
+	sr.NetProfit   = math.Trunc(sr.NetProfit)
+
+	return sr
+}
+
 //=============================================================================
 //===
 //=== Equity
@@ -259,25 +442,36 @@ func NewEquity(trades []*BiasTrade) *Equity {
 //=============================================================================
 
 type ProfitDistribution struct {
-	NetProfits [16]float64 `json:"netProfits"`
-	NumTrades  [16]int     `json:"numTrades"`
-	AvgTrades  [16]float64 `json:"avgTrades"`
+	NetProfits [16]float64  `json:"netProfits"`
+	NumTrades  [16]int      `json:"numTrades"`
+	AvgTrades  [16]float64  `json:"avgTrades"`
+	ExitCounts map[int8]int `json:"exitCounts"`
 }
 
 //=============================================================================
+//--- The thresholds are multiples of the commission, which is in the product's
+//--- currency. They are converted with the rate used for each trade
 
-func NewProfitDistribution(trades []*BiasTrade, costPerOperation float64) *ProfitDistribution {
+func NewProfitDistribution(trades []*BiasTrade, commission float64) *ProfitDistribution {
 	var netProfits[16]float64
 	var numTrades [16]int
 	var avgTrades [16]float64
 
-	threshold := calcThreshold(costPerOperation)
+	threshold  := calcThreshold(commission)
+	exitCounts := map[int8]int{}
 
 	for _, bt := range trades {
+		exitCounts[bt.ExitCondition]++
+
+		rate := 1.0
+		if bt.FxRate != 0 {
+			rate = bt.FxRate
+		}
+
 		found := false
 
 		for i:=0; i<15; i++ {
-			if bt.NetProfit < threshold[i] {
+			if bt.NetProfit < threshold[i] * rate {
 				netProfits[i] += bt.NetProfit
 				numTrades [i] += 1
 				found = true
@@ -305,6 +499,7 @@ func NewProfitDistribution(trades []*BiasTrade, costPerOperation float64) *Profi
 		NetProfits: netProfits,
 		NumTrades : numTrades,
 		AvgTrades : avgTrades,
+		ExitCounts: exitCounts,
 	}
 }
 
@@ -0,0 +1,57 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import "testing"
+
+//=============================================================================
+//--- With a commission of 2, thresholds go from -128 to +128. A converted trade
+//--- is compared with thresholds converted by its rate
+
+func TestProfitDistributionThresholds(t *testing.T) {
+	tests := []struct {
+		name      string
+		netProfit float64
+		fxRate    float64
+		expected  int
+	}{
+		{"no conversion, profit",  3,    0, 9  },
+		{"converted, profit",      3,    2, 8  },
+		{"no conversion, loss",   -5,    0, 5  },
+		{"converted, loss",       -5,    2, 6  },
+		{"above last threshold",  1000,  1, 15 },
+	}
+
+	for _, tt := range tests {
+		trade := &BiasTrade{ NetProfit: tt.netProfit, FxRate: tt.fxRate }
+		pd    := NewProfitDistribution([]*BiasTrade{ trade }, 2)
+
+		if pd.NumTrades[tt.expected] != 1 {
+			t.Errorf("%v: expected trade in bucket %v but got %v", tt.name, tt.expected, pd.NumTrades)
+		}
+	}
+}
+
+//=============================================================================
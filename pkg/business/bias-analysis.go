@@ -91,12 +91,22 @@ func GetBiasAnalysisById(tx *gorm.DB, c *auth.Context, id uint, details bool) (*
 func AddBiasAnalysis(tx *gorm.DB, c *auth.Context, bas *BiasAnalysisSpec) (*db.BiasAnalysis, error) {
 	c.Log.Info("AddBiasAnalysis: Adding a new bias analysis", "name", bas.Name)
 
+	if bas.SlotSize == 0 {
+		bas.SlotSize = DefaultSlotSize
+	}
+
+	if !isValidSlotSize(bas.SlotSize) {
+		c.Log.Error("AddBiasAnalysis: Invalid slot size", "slotSize", bas.SlotSize)
+		return nil, req.NewBadRequestError("Slot size must be 15, 30, 60 or 120: %v", bas.SlotSize)
+	}
+
 	var ba db.BiasAnalysis
 	ba.Username         = c.Session.Username
 	ba.DataInstrumentId = bas.DataInstrumentId
 	ba.BrokerProductId  = bas.BrokerProductId
 	ba.Name             = bas.Name
 	ba.Notes            = bas.Notes
+	ba.SlotSize         = bas.SlotSize
 
 	err := db.AddBiasAnalysis(tx, &ba)
 
@@ -119,10 +129,16 @@ func UpdateBiasAnalysis(tx *gorm.DB, c *auth.Context, id uint, bas *BiasAnalysis
 		return nil, err
 	}
 
+	err = checkSlotSizeChange(tx, c, ba, bas)
+	if err != nil {
+		return nil, err
+	}
+
 	ba.DataInstrumentId = bas.DataInstrumentId
 	ba.BrokerProductId  = bas.BrokerProductId
 	ba.Name             = bas.Name
 	ba.Notes            = bas.Notes
+	ba.SlotSize         = bas.SlotSize
 
 	err = db.UpdateBiasAnalysis(tx, ba)
 	if err != nil {
@@ -148,6 +164,8 @@ func DeleteBiasAnalysis(tx *gorm.DB, c *auth.Context, id uint) (*db.BiasAnalysis
 		return nil, err
 	}
 
+	removeCachedSummary(id)
+
 	return ba, nil
 }
 
@@ -181,3 +199,35 @@ func getBiasAnalysisAndCheckAccess(tx *gorm.DB, c *auth.Context, id uint, functi
 }
 
 //=============================================================================
+//--- Configs' slots are indexes that depend on the slot size, so the size cannot
+//--- change once configs have been added
+
+func checkSlotSizeChange(tx *gorm.DB, c *auth.Context, ba *db.BiasAnalysis, bas *BiasAnalysisSpec) error {
+	if bas.SlotSize == 0 {
+		bas.SlotSize = int16(getSlotSize(ba))
+	}
+
+	if !isValidSlotSize(bas.SlotSize) {
+		c.Log.Error("UpdateBiasAnalysis: Invalid slot size", "slotSize", bas.SlotSize)
+		return req.NewBadRequestError("Slot size must be 15, 30, 60 or 120: %v", bas.SlotSize)
+	}
+
+	if int(bas.SlotSize) == getSlotSize(ba) {
+		return nil
+	}
+
+	list, err := db.GetBiasConfigsByAnalysisId(tx, ba.Id)
+	if err != nil {
+		c.Log.Error("UpdateBiasAnalysis: Could not retrieve bias configs", "error", err.Error())
+		return err
+	}
+
+	if len(*list) > 0 {
+		c.Log.Error("UpdateBiasAnalysis: Cannot change slot size of an analysis with configs", "id", ba.Id)
+		return req.NewBadRequestError("Cannot change the slot size of a bias analysis with configs: %v", ba.Id)
+	}
+
+	return nil
+}
+
+//=============================================================================
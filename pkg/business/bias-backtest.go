@@ -26,9 +26,12 @@ package business
 
 import (
 	"errors"
+	"log/slog"
 	"time"
 
 	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/data-collector/pkg/core/indicator"
 	"github.com/bit-fever/data-collector/pkg/db"
 	"github.com/bit-fever/data-collector/pkg/ds"
 	"github.com/bit-fever/sick-engine/session"
@@ -41,10 +44,45 @@ import (
 //===
 //=============================================================================
 
+const (
+	TrailingTypePoints = "points"
+	TrailingTypeAtr    = "atr"
+
+	TrailingAtrPeriod = 14
+)
+
+//=============================================================================
+//--- Dates use the 'yyyy-mm-dd hh:mm:ss' format, in the product's timezone.
+//--- Trades entered from OosFrom onward are out of sample. TrailingStop is in
+//--- points or in ATR multiples, BreakevenAfter is in currency like StopLoss.
+//--- IntrabarResolution uses 1m bars to find which level was hit first when
+//--- a slot touches the stop or the target, EntryAtOpen enters at the open of
+//--- the first 1m bar of the slot. ExitAtSessionEnd closes open trades at the end
+//--- of the session, which must be given
+
 type BiasBacktestSpec struct {
-	StopLoss   float64                  `json:"stopLoss"`
-	TakeProfit float64                  `json:"takeProfit"`
-	Session    *session.TradingSession  `json:"session"`
+	StopLoss           float64                `json:"stopLoss"`
+	TakeProfit         float64                `json:"takeProfit"`
+	Session           *session.TradingSession `json:"session"`
+	Currency           string                 `json:"currency"`
+	From               string                 `json:"from"`
+	To                 string                 `json:"to"`
+	OosFrom            string                 `json:"oosFrom"`
+	MonteCarlo        *MonteCarloSpec         `json:"monteCarlo"`
+
+	TrailingStop       float64                `json:"trailingStop"`
+	TrailingType       string                 `json:"trailingType"`
+	BreakevenAfter     float64                `json:"breakevenAfter"`
+	MaxSlots           int                    `json:"maxSlots"`
+	ExitAtSessionEnd   bool                   `json:"exitAtSessionEnd"`
+
+	IntrabarResolution bool                   `json:"intrabarResolution"`
+	EntryAtOpen        bool                   `json:"entryAtOpen"`
+	Costs             *CostModelSpec          `json:"costs"`
+
+	from               time.Time
+	to                 time.Time
+	oosFrom           *time.Time
 }
 
 //=============================================================================
@@ -54,7 +92,16 @@ type BiasBacktestResponse struct {
 	BrokerProduct     *db.BrokerProduct    `json:"brokerProduct"`
 	Spec              *BiasBacktestSpec    `json:"spec"`
 	BacktestedConfigs []*BacktestedConfig  `json:"backtestedConfigs"`
+	Portfolio         *BacktestPortfolio   `json:"portfolio"`
 	config            *DataConfig
+	fx                *FxSource
+}
+
+//--- The combined trades of all configs of the analysis
+
+type BacktestPortfolio struct {
+	Result  *SampleResult       `json:"result"`
+	Metrics *PerformanceMetrics `json:"metrics"`
 }
 
 //=============================================================================
@@ -91,29 +138,50 @@ func GetBacktestInfo(tx *gorm.DB, c *auth.Context, id uint, spec *BiasBacktestSp
 		return nil, err
 	}
 
+	if bp == nil {
+		c.Log.Error("GetBacktestInfo: Broker product was not found", "id", ba.BrokerProductId)
+		return nil, req.NewNotFoundError("Broker product was not found: %v", ba.BrokerProductId)
+	}
+
+	//--- Profits are converted at exit time if another currency is requested
+
+	var fx *FxSource
+	if spec.Currency != "" && spec.Currency != bp.CurrencyCode {
+		fx, err = getFxSource(tx, c, bp.CurrencyCode, spec.Currency)
+		if err != nil {
+			c.Log.Error("GetBacktestInfo: Could not find FX source", "error", err.Error())
+			return nil, err
+		}
+	}
+
+	err = checkSpec(c, spec, config.Timezone, bp)
+	if err != nil {
+		return nil,err
+	}
+
 	var btConfigs []*BacktestedConfig
 
+	loc,_   := time.LoadLocation(config.Timezone)
+	calendar:= newSessionCalendar(spec.Session, loc)
+
 	for _, bc := range *biasConfigs {
-		btc, err := NewBacktestedConfig(bc, bp, spec)
+		btc, err := NewBacktestedConfig(bc, bp, spec, getSlotSize(ba))
 		if err != nil {
 			c.Log.Error("GetBacktestInfo: Could not build backtested config", "error", err.Error())
 			return nil, err
 		}
 
+		btc.calendar = calendar
 		btConfigs = append(btConfigs, btc)
 	}
 
-	err = checkSpec(c, spec)
-	if err != nil {
-		return nil,err
-	}
-
 	return &BiasBacktestResponse{
 		BiasAnalysis     : ba,
 		BrokerProduct    : bp,
 		Spec             : spec,
 		BacktestedConfigs: btConfigs,
 		config           : config,
+		fx               : fx,
 	}, nil
 }
 
@@ -122,44 +190,127 @@ func GetBacktestInfo(tx *gorm.DB, c *auth.Context, id uint, spec *BiasBacktestSp
 func RunBacktest(c *auth.Context, bbr *BiasBacktestResponse) error {
 	c.Log.Info("RunBacktest: Starting backtest for bias analysis", "id", bbr.BiasAnalysis.Id)
 
-	bbr.config.DataConfig.Timeframe = "15m"
-
-	loc,_:= time.LoadLocation(bbr.config.Timezone)
-	da   := ds.NewDataAggregator(ds.TimeSlotFunction30m, loc)
-	err  := ds.GetDataPoints(DefaultFrom, DefaultTo, &bbr.config.DataConfig, loc, da)
+	slotSize := getSlotSize(bbr.BiasAnalysis)
 
+	dataPoints, fxc, err := getBacktestData(c.Log, bbr, slotSize)
 	if err != nil {
-		c.Log.Error("RunBacktest: Could not retrieve data points", "error", err.Error())
 		return err
 	}
 
-	dataPoints := da.DataPoints()
+	ms := newMinuteSource(bbr)
+
+	for _, btc := range bbr.BacktestedConfigs {
+		btc.fxConverter = fxc
+		btc.minutes     = ms
+	}
+
+	runBacktestedConfigs(bbr.BacktestedConfigs, dataPoints, slotSize)
+
+	if ms != nil && ms.err != nil {
+		c.Log.Error("RunBacktest: Could not retrieve 1m data points", "error", ms.err.Error())
+		return ms.err
+	}
+
+	trades := mergeTrades(bbr.BacktestedConfigs)
+	bbr.Portfolio = &BacktestPortfolio{
+		Result : NewSampleResult(trades),
+		Metrics: NewPerformanceMetrics(trades),
+	}
+
+	if bbr.Spec.MonteCarlo != nil {
+		for _, btc := range bbr.BacktestedConfigs {
+			btc.MonteCarlo = NewMonteCarloResult(btc.Trades, bbr.Spec.MonteCarlo)
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+//--- The FX converter is nil when profits are not converted. A logger is used in
+//--- place of the context, as background scans call it after the request ended
+
+func getBacktestData(log *slog.Logger, bbr *BiasBacktestResponse, slotSize int) ([]*ds.DataPoint, *FxConverter, error) {
+	loc,_ := time.LoadLocation(bbr.config.Timezone)
+	da    := newSlotAggregator(bbr.config, slotSize, loc)
+	params:= newBacktestDataParams(loc, da, bbr.Spec)
+
+	dataPoints, err := getDataPoints(params, bbr.config)
+	if err != nil {
+		log.Error("getBacktestData: Could not retrieve data points", "error", err.Error())
+		return nil, nil, err
+	}
+
+	var fxc *FxConverter
+	if bbr.fx != nil {
+		fxc, err = bbr.fx.newConverter(newBacktestDataParams(loc, da, bbr.Spec), bbr.config.DataConfig.Timeframe)
+		if err != nil {
+			log.Error("getBacktestData: Could not retrieve FX data points", "error", err.Error())
+			return nil, nil, err
+		}
+	}
+
+	return dataPoints, fxc, nil
+}
+
+//=============================================================================
+
+func runBacktestedConfigs(configs []*BacktestedConfig, dataPoints []*ds.DataPoint, slotSize int) {
+	prepareBacktestedConfigs(configs, dataPoints, nil)
 
 	for i, dp := range dataPoints {
 		if i>0 {
 			prevDp := dataPoints[i-1]
-			ti     := calcTimeInfo(dp)
+			ti     := calcTimeInfo(dp, slotSize)
 
-			for _, btc := range bbr.BacktestedConfigs {
+			for _, btc := range configs {
 				btc.RunBacktest(ti, dp, prevDp, i, dataPoints)
 			}
 		}
 	}
 
-	for _, btc := range bbr.BacktestedConfigs {
+	for _, btc := range configs {
 		btc.Finish()
 	}
+}
 
-	return nil
+//=============================================================================
+//--- Sets the ATR on configs that trail by ATR and don't have it yet. It is
+//--- computed only if needed and returned, so that batches on the same data can
+//--- pass it again
+
+func prepareBacktestedConfigs(configs []*BacktestedConfig, dataPoints []*ds.DataPoint, atr []float64) []float64 {
+	for _, btc := range configs {
+		if btc.spec.TrailingType == TrailingTypeAtr && btc.atr == nil {
+			if atr == nil {
+				atr = indicator.ATR(dataPoints, TrailingAtrPeriod)
+			}
+
+			btc.atr = atr
+		}
+	}
+
+	return atr
 }
 
 //=============================================================================
-//===
-//=== Private functions
-//===
+
+func newBacktestDataParams(loc *time.Location, da *ds.DataAggregator, spec *BiasBacktestSpec) *DataInstrumentDataParams {
+	params := newDefaultDataParams(loc, da)
+	params.From = spec.from
+	params.To   = spec.to
+
+	return params
+}
+
 //=============================================================================
 
-func checkSpec(c *auth.Context, bts *BiasBacktestSpec) error {
+func checkSpec(c *auth.Context, bts *BiasBacktestSpec, timezone string, bp *db.BrokerProduct) error {
 	var err error
 
 	if bts.StopLoss < 0 {
@@ -174,21 +325,124 @@ func checkSpec(c *auth.Context, bts *BiasBacktestSpec) error {
 		return err
 	}
 
+	err = checkExits(bts)
+	if err != nil {
+		c.Log.Error("createParams: Invalid exits", "error", err.Error())
+		return err
+	}
+
+	if bts.Session != nil {
+		err = checkSession(bts.Session)
+		if err != nil {
+			c.Log.Error("createParams: Invalid session", "error", err.Error())
+			return err
+		}
+	}
+
+	if bts.Costs != nil {
+		err = checkCostModelSpec(bts.Costs, bp)
+		if err != nil {
+			c.Log.Error("createParams: Invalid cost model", "error", err.Error())
+			return err
+		}
+	}
+
+	if bts.MonteCarlo != nil {
+		err = checkMonteCarloSpec(bts.MonteCarlo)
+		if err != nil {
+			c.Log.Error("createParams: Invalid Monte Carlo spec", "error", err.Error())
+			return err
+		}
+	}
+
+	return checkPeriod(c, bts, timezone)
+}
+
+//=============================================================================
+
+func checkExits(bts *BiasBacktestSpec) error {
+	if bts.TrailingType == "" {
+		bts.TrailingType = TrailingTypePoints
+	}
+
+	if bts.TrailingType != TrailingTypePoints && bts.TrailingType != TrailingTypeAtr {
+		return errors.New("trailingType must be points or atr: "+ bts.TrailingType)
+	}
+
+	if bts.TrailingStop < 0 {
+		return errors.New("trailingStop cannot be negative")
+	}
+
+	if bts.BreakevenAfter < 0 {
+		return errors.New("breakevenAfter cannot be negative")
+	}
+
+	if bts.MaxSlots < 0 {
+		return errors.New("maxSlots cannot be negative")
+	}
+
+	if bts.ExitAtSessionEnd && bts.Session == nil {
+		return errors.New("exitAtSessionEnd requires a session")
+	}
+
 	return nil
 }
 
 //=============================================================================
 
-func calcTimeInfo(dp *ds.DataPoint) *TimeInfo {
-	//--- Calc slot time from destination to take into account leaps when markets
-	//--- are closed (i.e. slot 16:00 - 17:30 will have 16:00 instead of 17:00)
+func checkPeriod(c *auth.Context, bts *BiasBacktestSpec, timezone string) error {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return err
+	}
+
+	bts.from, err = parseTime(bts.From, DefaultFrom, loc)
+	if err != nil {
+		c.Log.Error("checkPeriod: Invalid from", "from", bts.From)
+		return errors.New("invalid from date: "+ bts.From)
+	}
+
+	bts.to, err = parseTime(bts.To, DefaultTo, loc)
+	if err != nil {
+		c.Log.Error("checkPeriod: Invalid to", "to", bts.To)
+		return errors.New("invalid to date: "+ bts.To)
+	}
+
+	if !bts.from.Before(bts.to) {
+		err = errors.New("from must be before to")
+		c.Log.Error("checkPeriod: Invalid period", "error", err.Error())
+		return err
+	}
+
+	if bts.OosFrom != "" {
+		var oosFrom time.Time
+		oosFrom, err = parseTime(bts.OosFrom, DefaultFrom, loc)
+		if err != nil {
+			c.Log.Error("checkPeriod: Invalid oosFrom", "oosFrom", bts.OosFrom)
+			return errors.New("invalid oosFrom date: "+ bts.OosFrom)
+		}
+
+		if !oosFrom.After(bts.from) || !oosFrom.Before(bts.to) {
+			err = errors.New("oosFrom must be inside the from-to period")
+			c.Log.Error("checkPeriod: Invalid oosFrom", "error", err.Error())
+			return err
+		}
+
+		bts.oosFrom = &oosFrom
+	}
+
+	return nil
+}
+
+//=============================================================================
 
-	slotTime := dp.Time.Add(-time.Minute * 30)
+func calcTimeInfo(dp *ds.DataPoint, slotSize int) *TimeInfo {
+	slotTime := getSlotTime(dp, slotSize)
 
 	year,month,_ := slotTime.Date()
 	hour,mins, _ := slotTime.Clock()
 	dow          := slotTime.Weekday()
-	slot         := (hour * 60 + mins) / 30
+	slot         := getSlot(hour, mins, slotSize)
 
 	return &TimeInfo{
 		dayOfWeek: int16(dow),
@@ -26,6 +26,7 @@ package business
 
 import (
 	"errors"
+	"strconv"
 	"github.com/bit-fever/core/auth"
 	"github.com/bit-fever/data-collector/pkg/db"
 	"gorm.io/gorm"
@@ -57,7 +58,7 @@ func GetBiasConfigsByAnalysisId(tx *gorm.DB, c *auth.Context, baId uint) (*[]*Bi
 func AddBiasConfig(tx *gorm.DB, c *auth.Context, baId uint, bcs *BiasConfigSpec) (*db.BiasConfig, error) {
 	c.Log.Info("AddBiasConfig: Adding a new bias config", "baId", baId)
 
-	if err:=checkBiasConfigSpec(c, bcs); err != nil {
+	if err:=checkBiasConfigSpec(tx, c, baId, bcs); err != nil {
 		return nil, err
 	}
 
@@ -79,7 +80,7 @@ func AddBiasConfig(tx *gorm.DB, c *auth.Context, baId uint, bcs *BiasConfigSpec)
 func UpdateBiasConfig(tx *gorm.DB, c *auth.Context, baId uint, id uint, bcs *BiasConfigSpec) (*db.BiasConfig, error) {
 	c.Log.Info("UpdateBiasConfig: Updating a bias config", "id", id, "baId", baId)
 
-	if err:=checkBiasConfigSpec(c, bcs); err != nil {
+	if err:=checkBiasConfigSpec(tx, c, baId, bcs); err != nil {
 		return nil, err
 	}
 
@@ -118,13 +119,26 @@ func DeleteBiasConfig(tx *gorm.DB, c *auth.Context, baId uint, id uint) (bool, e
 //===
 //=============================================================================
 
-func checkBiasConfigSpec(c *auth.Context, bcs *BiasConfigSpec) error {
+func checkBiasConfigSpec(tx *gorm.DB, c *auth.Context, baId uint, bcs *BiasConfigSpec) error {
 	if bcs.Operation != 0 && bcs.Operation != 1 {
 		err := errors.New("operation can only be 0 (for long) or 1 (for short)")
 		c.Log.Error("checkBiasConfigSpec: Invalid bias config spec", "error", err.Error())
 		return err
 	}
 
+	ba, err := getBiasAnalysisAndCheckAccess(tx, c, baId, "checkBiasConfigSpec")
+	if err != nil {
+		return err
+	}
+
+	slotsPerDay := int16(getSlotsPerDay(getSlotSize(ba)))
+
+	if bcs.StartSlot < 0 || bcs.StartSlot >= slotsPerDay || bcs.EndSlot < 0 || bcs.EndSlot >= slotsPerDay {
+		err = errors.New("slots must be in the range 0.."+ strconv.Itoa(int(slotsPerDay -1)))
+		c.Log.Error("checkBiasConfigSpec: Invalid bias config spec", "error", err.Error())
+		return err
+	}
+
 	return nil
 }
 
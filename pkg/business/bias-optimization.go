@@ -0,0 +1,286 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/data-collector/pkg/core"
+	"github.com/bit-fever/data-collector/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+const MaxGridValues = 20
+
+//=============================================================================
+//===
+//=== Structures
+//===
+//=============================================================================
+//--- StopLoss and TakeProfit of the backtest spec are ignored and replaced by
+//--- the grid values. A value of 0 means "none" and is always the last one of the grid
+
+type OptimizationSpec struct {
+	Backtest    BiasBacktestSpec `json:"backtest"`
+	StopLosses  []float64        `json:"stopLosses"`
+	TakeProfits []float64        `json:"takeProfits"`
+}
+
+//=============================================================================
+
+type OptimizationResponse struct {
+	BiasAnalysis  *db.BiasAnalysis      `json:"biasAnalysis"`
+	BrokerProduct *db.BrokerProduct     `json:"brokerProduct"`
+	Spec          *OptimizationSpec     `json:"spec"`
+	StopLosses    []float64             `json:"stopLosses"`
+	TakeProfits   []float64             `json:"takeProfits"`
+	Configs       []*OptimizationConfig `json:"configs"`
+
+	backtest      *BiasBacktestResponse
+}
+
+//=============================================================================
+//--- Cells are indexed as [stopLoss][takeProfit], following the order of the
+//--- grid values in the response. Best is the cell with the highest robustness
+
+type OptimizationConfig struct {
+	BiasConfig *BiasConfig           `json:"biasConfig"`
+	Cells      [][]*OptimizationCell `json:"cells"`
+	Best       *OptimizationCell     `json:"best"`
+}
+
+//=============================================================================
+//--- Robustness is the mean net profit of the cell and its neighbours minus
+//--- their standard deviation, so that a plateau of good values scores better
+//--- than an isolated peak
+
+type OptimizationCell struct {
+	StopLoss     float64 `json:"stopLoss"`
+	TakeProfit   float64 `json:"takeProfit"`
+	NumTrades    int     `json:"numTrades"`
+	NetProfit    float64 `json:"netProfit"`
+	MaxDrawdown  float64 `json:"maxDrawdown"`
+	ProfitFactor float64 `json:"profitFactor"`
+	Robustness   float64 `json:"robustness"`
+}
+
+//=============================================================================
+//===
+//=== Functions
+//===
+//=============================================================================
+
+func GetOptimizationInfo(tx *gorm.DB, c *auth.Context, id uint, spec *OptimizationSpec) (*OptimizationResponse, error) {
+	stopLosses, err := buildGridValues(spec.StopLosses)
+	if err != nil {
+		c.Log.Error("GetOptimizationInfo: Invalid stop losses", "error", err.Error())
+		return nil, req.NewBadRequestError("Invalid stop losses: %v", err.Error())
+	}
+
+	takeProfits, err := buildGridValues(spec.TakeProfits)
+	if err != nil {
+		c.Log.Error("GetOptimizationInfo: Invalid take profits", "error", err.Error())
+		return nil, req.NewBadRequestError("Invalid take profits: %v", err.Error())
+	}
+
+	spec.Backtest.StopLoss   = 0
+	spec.Backtest.TakeProfit = 0
+	spec.Backtest.MonteCarlo = nil
+
+	bbr, err := GetBacktestInfo(tx, c, id, &spec.Backtest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OptimizationResponse{
+		BiasAnalysis : bbr.BiasAnalysis,
+		BrokerProduct: bbr.BrokerProduct,
+		Spec         : spec,
+		StopLosses   : stopLosses,
+		TakeProfits  : takeProfits,
+		Configs      : []*OptimizationConfig{},
+		backtest     : bbr,
+	}, nil
+}
+
+//=============================================================================
+//--- Data points are loaded once and shared by all grid runs. The grid of each
+//--- config is run in a single pass over the data, one config at a time to
+//--- keep the trades in memory bounded
+
+func RunOptimization(c *auth.Context, opr *OptimizationResponse) error {
+	c.Log.Info("RunOptimization: Starting optimization for bias analysis", "id", opr.BiasAnalysis.Id)
+
+	bbr      := opr.backtest
+	slotSize := getSlotSize(bbr.BiasAnalysis)
+
+	dataPoints, fxc, err := getBacktestData(c.Log, bbr, slotSize)
+	if err != nil {
+		return err
+	}
+
+	ms := newMinuteSource(bbr)
+
+	for _, base := range bbr.BacktestedConfigs {
+		var grid []*BacktestedConfig
+
+		for _, sl := range opr.StopLosses {
+			for _, tp := range opr.TakeProfits {
+				spec := *bbr.Spec
+				spec.StopLoss   = sl
+				spec.TakeProfit = tp
+
+				btc, err := NewBacktestedConfig(base.BiasConfig, bbr.BrokerProduct, &spec, slotSize)
+				if err != nil {
+					c.Log.Error("RunOptimization: Could not build backtested config", "error", err.Error())
+					return err
+				}
+
+				btc.fxConverter = fxc
+				btc.minutes     = ms
+				btc.calendar    = base.calendar
+				btc.noSequences = true
+				grid = append(grid, btc)
+			}
+		}
+
+		runBacktestedConfigs(grid, dataPoints, slotSize)
+
+		if ms != nil && ms.err != nil {
+			c.Log.Error("RunOptimization: Could not retrieve 1m data points", "error", ms.err.Error())
+			return ms.err
+		}
+
+		opr.Configs = append(opr.Configs, newOptimizationConfig(base.BiasConfig, grid, len(opr.TakeProfits)))
+	}
+
+	c.Log.Info("RunOptimization: Optimization completed", "id", opr.BiasAnalysis.Id, "cells", len(opr.StopLosses) * len(opr.TakeProfits))
+	return nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func newOptimizationConfig(bc *BiasConfig, grid []*BacktestedConfig, cols int) *OptimizationConfig {
+	oc := &OptimizationConfig{
+		BiasConfig: bc,
+		Cells     : [][]*OptimizationCell{},
+	}
+
+	for i, btc := range grid {
+		if i % cols == 0 {
+			oc.Cells = append(oc.Cells, []*OptimizationCell{})
+		}
+
+		row := len(oc.Cells) -1
+		oc.Cells[row] = append(oc.Cells[row], &OptimizationCell{
+			StopLoss    : btc.spec.StopLoss,
+			TakeProfit  : btc.spec.TakeProfit,
+			NumTrades   : len(btc.Trades),
+			NetProfit   : btc.NetProfit,
+			MaxDrawdown : btc.Metrics.MaxDrawdown,
+			ProfitFactor: btc.Metrics.ProfitFactor,
+		})
+	}
+
+	for r, row := range oc.Cells {
+		for k, cell := range row {
+			cell.Robustness = calcRobustness(oc.Cells, r, k)
+
+			if cell.NumTrades > 0 && (oc.Best == nil || cell.Robustness > oc.Best.Robustness) {
+				oc.Best = cell
+			}
+		}
+	}
+
+	return oc
+}
+
+//=============================================================================
+//--- Uses the 3x3 neighbourhood of the cell, clipped at the grid borders
+
+func calcRobustness(cells [][]*OptimizationCell, row, col int) float64 {
+	var values []float64
+
+	for r := max(row -1, 0); r <= min(row +1, len(cells) -1); r++ {
+		for k := max(col -1, 0); k <= min(col +1, len(cells[r]) -1); k++ {
+			values = append(values, cells[r][k].NetProfit)
+		}
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+
+	mean := sum / float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+
+	stdDev := math.Sqrt(variance / float64(len(values)))
+
+	return core.Trunc2d(mean - stdDev)
+}
+
+//=============================================================================
+//--- Returns the sorted values without duplicates. 0 (none) is the limit of an
+//--- ever larger value, so it comes last and is the neighbour of the largest one
+
+func buildGridValues(values []float64) ([]float64, error) {
+	if len(values) > MaxGridValues {
+		return nil, errors.New("at most "+ strconv.Itoa(MaxGridValues) +" values are allowed")
+	}
+
+	var list []float64
+
+	for _, v := range values {
+		if v < 0 {
+			return nil, errors.New("values cannot be negative")
+		}
+
+		if v > 0 {
+			list = append(list, v)
+		}
+	}
+
+	slices.Sort(list)
+
+	return append(slices.Compact(list), 0), nil
+}
+
+//=============================================================================
@@ -0,0 +1,629 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/data-collector/pkg/ds"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+const (
+	ScanStatusRunning   = "running"
+	ScanStatusCompleted = "completed"
+	ScanStatusFailed    = "failed"
+	ScanStatusCancelled = "cancelled"
+
+	ScanMetricNetProfit    = "netProfit"
+	ScanMetricAvgTrade     = "avgTrade"
+	ScanMetricProfitFactor = "profitFactor"
+	ScanMetricSharpe       = "sharpe"
+	ScanMetricWinRate      = "winRate"
+
+	MaxScanSpanDays     = 5
+	MaxScanCandidates   = 100000
+	MaxScanTopN         = 100
+	MaxRunningScans     = 2
+	DefaultScanTopN     = 20
+	DefaultScanMinTrades= 30
+
+	//--- Candidates are backtested in batches to limit the memory used by trades
+	ScanBatchSize = 500
+
+	//--- Deleted jobs are stopped after, at most, this number of bars
+	ScanCancelCheckBars = 1000
+
+	//--- Finished jobs are removed after this time
+	ScanJobRetention = time.Hour
+)
+
+//=============================================================================
+//===
+//=== Structures
+//===
+//=============================================================================
+//--- MaxSpan is the maximum holding time in slots. Each month mask generates
+//--- a set of candidates (no masks means all months)
+
+type BiasScanSpec struct {
+	Backtest   BiasBacktestSpec `json:"backtest"`
+	MaxSpan    int              `json:"maxSpan"`
+	Operations []int8           `json:"operations"`
+	MonthMasks [][]bool         `json:"monthMasks"`
+	Excludes   []string         `json:"excludes"`
+	Metric     string           `json:"metric"`
+	MinTrades  int              `json:"minTrades"`
+	TopN       int              `json:"topN"`
+}
+
+//=============================================================================
+
+type BiasScanJob struct {
+	Id             uint             `json:"id"`
+	BiasAnalysisId uint             `json:"biasAnalysisId"`
+	Username       string           `json:"username"`
+	Status         string           `json:"status"`
+	Progress       int              `json:"progress"`
+	Candidates     int              `json:"candidates"`
+	Error          string           `json:"error,omitempty"`
+	StartedAt      time.Time        `json:"startedAt"`
+	EndedAt        *time.Time       `json:"endedAt,omitempty"`
+	Spec           *BiasScanSpec    `json:"spec"`
+	Results        []*ScanCandidate `json:"results"`
+
+	backtest       *BiasBacktestResponse
+	cancelled      bool
+}
+
+//=============================================================================
+//--- Config can be saved as it is with the bias config API
+
+type ScanCandidate struct {
+	Config       *BiasConfigSpec `json:"config"`
+	Score        float64         `json:"score"`
+	NumTrades    int             `json:"numTrades"`
+	NetProfit    float64         `json:"netProfit"`
+	NetAvgTrade  float64         `json:"netAvgTrade"`
+	ProfitFactor float64         `json:"profitFactor"`
+	WinRate      float64         `json:"winRate"`
+	SharpeRatio  float64         `json:"sharpeRatio"`
+	MaxDrawdown  float64         `json:"maxDrawdown"`
+}
+
+//=============================================================================
+//--- Jobs are kept in memory by the instance that started them: they are lost
+//--- on restart and can be read only from that instance. This works as long as
+//--- a single data-collector is running
+
+var scanJobs = map[uint]*BiasScanJob{}
+var scanJobsMutex sync.Mutex
+var scanJobsLastId uint
+
+//=============================================================================
+//===
+//=== Functions
+//===
+//=============================================================================
+
+func StartBiasScan(tx *gorm.DB, c *auth.Context, id uint, spec *BiasScanSpec) (*BiasScanJob, error) {
+	c.Log.Info("StartBiasScan: Starting a scan for bias analysis", "id", id)
+
+	bbr, err := GetBacktestInfo(tx, c, id, &spec.Backtest)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkScanSpec(spec, getSlotSize(bbr.BiasAnalysis))
+	if err != nil {
+		c.Log.Error("StartBiasScan: Invalid scan spec", "error", err.Error())
+		return nil, req.NewBadRequestError("Invalid scan spec: %v", err.Error())
+	}
+
+	scanJobsMutex.Lock()
+	defer scanJobsMutex.Unlock()
+
+	removeOldScanJobs()
+
+	if countRunningScanJobs() >= MaxRunningScans {
+		c.Log.Error("StartBiasScan: Too many running scans")
+		return nil, req.NewBadRequestError("Too many running scans. Please retry later")
+	}
+
+	scanJobsLastId++
+
+	job := &BiasScanJob{
+		Id            : scanJobsLastId,
+		BiasAnalysisId: id,
+		Username      : c.Session.Username,
+		Status        : ScanStatusRunning,
+		StartedAt     : time.Now(),
+		Spec          : spec,
+		Results       : []*ScanCandidate{},
+		backtest      : bbr,
+	}
+
+	scanJobs[job.Id] = job
+
+	go job.run(c.Log)
+
+	return job.snapshot(), nil
+}
+
+//=============================================================================
+
+func GetBiasScanJob(tx *gorm.DB, c *auth.Context, id uint, jobId uint) (*BiasScanJob, error) {
+	_, err := getBiasAnalysisAndCheckAccess(tx, c, id, "GetBiasScanJob")
+	if err != nil {
+		return nil, err
+	}
+
+	scanJobsMutex.Lock()
+	defer scanJobsMutex.Unlock()
+
+	job, err := getScanJob(c, id, jobId, "GetBiasScanJob")
+	if err != nil {
+		return nil, err
+	}
+
+	return job.snapshot(), nil
+}
+
+//=============================================================================
+//--- Running jobs are stopped within a few bars of the current batch
+
+func DeleteBiasScanJob(tx *gorm.DB, c *auth.Context, id uint, jobId uint) (*BiasScanJob, error) {
+	_, err := getBiasAnalysisAndCheckAccess(tx, c, id, "DeleteBiasScanJob")
+	if err != nil {
+		return nil, err
+	}
+
+	scanJobsMutex.Lock()
+	defer scanJobsMutex.Unlock()
+
+	job, err := getScanJob(c, id, jobId, "DeleteBiasScanJob")
+	if err != nil {
+		return nil, err
+	}
+
+	job.cancelled = true
+	delete(scanJobs, jobId)
+
+	c.Log.Info("DeleteBiasScanJob: Scan job deleted", "id", id, "jobId", jobId)
+	return job.snapshot(), nil
+}
+
+//=============================================================================
+//===
+//=== Private methods
+//===
+//=============================================================================
+
+//--- The job outlives the request, so only its logger is kept
+
+func (job *BiasScanJob) run(log *slog.Logger) {
+	bbr      := job.backtest
+	slotSize := getSlotSize(bbr.BiasAnalysis)
+
+	dataPoints, fxc, err := getBacktestData(log, bbr, slotSize)
+	if err != nil {
+		job.finish(log, ScanStatusFailed, err)
+		return
+	}
+
+	loc,_    := time.LoadLocation(bbr.config.Timezone)
+	calendar := newSessionCalendar(bbr.Spec.Session, loc)
+	ms       := newMinuteSource(bbr)
+
+	candidates := job.buildCandidates(dataPoints, slotSize)
+
+	var atr []float64
+	job.update(func() {
+		job.Candidates = len(candidates)
+	})
+
+	log.Info("BiasScanJob: Candidates built", "jobId", job.Id, "candidates", len(candidates))
+
+	for start := 0; start < len(candidates); start += ScanBatchSize {
+		if job.isCancelled() {
+			job.finish(log, ScanStatusCancelled, nil)
+			return
+		}
+
+		var configs []*BacktestedConfig
+
+		for _, bc := range candidates[start:min(start + ScanBatchSize, len(candidates))] {
+			btc, err := NewBacktestedConfig(bc, bbr.BrokerProduct, bbr.Spec, slotSize)
+			if err != nil {
+				job.finish(log, ScanStatusFailed, err)
+				return
+			}
+
+			btc.fxConverter = fxc
+			btc.minutes     = ms
+			btc.calendar    = calendar
+			btc.noSequences = true
+			configs = append(configs, btc)
+		}
+
+		atr = prepareBacktestedConfigs(configs, dataPoints, atr)
+		if !runScanBatch(configs, dataPoints, slotSize, job.isCancelled) {
+			job.finish(log, ScanStatusCancelled, nil)
+			return
+		}
+
+		job.update(func() {
+			for _, btc := range configs {
+				job.addResult(btc)
+			}
+
+			job.Progress = min(start + ScanBatchSize, len(candidates)) * 100 / len(candidates)
+		})
+	}
+
+	if ms != nil && ms.err != nil {
+		job.finish(log, ScanStatusFailed, ms.err)
+		return
+	}
+
+	job.finish(log, ScanStatusCompleted, nil)
+}
+
+//=============================================================================
+//--- Only days that have data are used as start and end days
+
+func (job *BiasScanJob) buildCandidates(dataPoints []*ds.DataPoint, slotSize int) []*BiasConfig {
+	var days [7]bool
+	for _, dp := range dataPoints {
+		days[getSlotTime(dp, slotSize).Weekday()] = true
+	}
+
+	spd       := getSlotsPerDay(slotSize)
+	weekSlots := 7 * spd
+
+	var list []*BiasConfig
+
+	for _, months := range job.Spec.MonthMasks {
+		for _, op := range job.Spec.Operations {
+			for start := 0; start < weekSlots; start++ {
+				if !days[start / spd] {
+					continue
+				}
+
+				for span := 1; span <= job.Spec.MaxSpan; span++ {
+					end := (start + span -1) % weekSlots
+					if !days[end / spd] {
+						continue
+					}
+
+					list = append(list, &BiasConfig{
+						BiasConfigSpec: BiasConfigSpec{
+							StartDay : int16(start / spd),
+							StartSlot: int16(start % spd),
+							EndDay   : int16(end / spd),
+							EndSlot  : int16(end % spd),
+							Months   : months,
+							Excludes : job.Spec.Excludes,
+							Operation: op,
+						},
+					})
+				}
+			}
+		}
+	}
+
+	return list
+}
+
+//=============================================================================
+//--- Keeps the results sorted by score, up to TopN items. Must be called
+//--- with the lock
+
+func (job *BiasScanJob) addResult(btc *BacktestedConfig) {
+	numTrades := len(btc.Trades)
+	if numTrades < job.Spec.MinTrades {
+		return
+	}
+
+	score := getScanScore(btc, job.Spec.Metric)
+	if len(job.Results) == job.Spec.TopN && score <= job.Results[len(job.Results) -1].Score {
+		return
+	}
+
+	config := btc.BiasConfig.BiasConfigSpec
+	config.GrossProfit = btc.GrossProfit
+	config.NetProfit   = btc.NetProfit
+
+	sc := &ScanCandidate{
+		Config      : &config,
+		Score       : score,
+		NumTrades   : numTrades,
+		NetProfit   : btc.NetProfit,
+		NetAvgTrade : btc.NetAvgTrade,
+		ProfitFactor: btc.Metrics.ProfitFactor,
+		WinRate     : btc.Metrics.WinRate,
+		SharpeRatio : btc.Metrics.SharpeRatio,
+		MaxDrawdown : btc.Metrics.MaxDrawdown,
+	}
+
+	pos, _ := slices.BinarySearchFunc(job.Results, score, func(item *ScanCandidate, s float64) int {
+		if item.Score > s {
+			return -1
+		}
+		if item.Score < s {
+			return +1
+		}
+		return 0
+	})
+
+	job.Results = slices.Insert(job.Results, pos, sc)
+	if len(job.Results) > job.Spec.TopN {
+		job.Results = job.Results[:job.Spec.TopN]
+	}
+}
+
+//=============================================================================
+
+func (job *BiasScanJob) finish(log *slog.Logger, status string, err error) {
+	job.update(func() {
+		now := time.Now()
+		job.Status  = status
+		job.EndedAt = &now
+
+		if err != nil {
+			job.Error = err.Error()
+		}
+
+		if status == ScanStatusCompleted {
+			job.Progress = 100
+		}
+	})
+
+	if err != nil {
+		log.Error("BiasScanJob: Scan failed", "jobId", job.Id, "error", err.Error())
+	} else {
+		log.Info("BiasScanJob: Scan ended", "jobId", job.Id, "status", status)
+	}
+}
+
+//=============================================================================
+
+func (job *BiasScanJob) update(f func()) {
+	scanJobsMutex.Lock()
+	defer scanJobsMutex.Unlock()
+
+	f()
+}
+
+//=============================================================================
+
+func (job *BiasScanJob) isCancelled() bool {
+	scanJobsMutex.Lock()
+	defer scanJobsMutex.Unlock()
+
+	return job.cancelled
+}
+
+//=============================================================================
+//--- Must be called with the lock
+
+func (job *BiasScanJob) snapshot() *BiasScanJob {
+	s := *job
+	s.Results = slices.Clone(job.Results)
+
+	return &s
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func checkScanSpec(spec *BiasScanSpec, slotSize int) error {
+	spd := getSlotsPerDay(slotSize)
+
+	if spec.MaxSpan < 1 || spec.MaxSpan > spd * MaxScanSpanDays {
+		return errors.New("maxSpan must be in the range 1.."+ strconv.Itoa(spd * MaxScanSpanDays))
+	}
+
+	if len(spec.Operations) == 0 {
+		spec.Operations = []int8{ 0, 1 }
+	}
+
+	for _, op := range spec.Operations {
+		if op != 0 && op != 1 {
+			return errors.New("operations must be 0 (long) or 1 (short)")
+		}
+	}
+
+	if len(spec.MonthMasks) == 0 {
+		spec.MonthMasks = [][]bool{ slices.Repeat([]bool{ true }, 12) }
+	}
+
+	for _, mask := range spec.MonthMasks {
+		if len(mask) != 12 {
+			return errors.New("month masks must have 12 items")
+		}
+	}
+
+	if _, err := NewExcludedSet(spec.Excludes); err != nil {
+		return err
+	}
+
+	if spec.Metric == "" {
+		spec.Metric = ScanMetricNetProfit
+	}
+
+	switch spec.Metric {
+		case ScanMetricNetProfit, ScanMetricAvgTrade, ScanMetricProfitFactor, ScanMetricSharpe, ScanMetricWinRate:
+		default:
+			return errors.New("unknown metric: "+ spec.Metric)
+	}
+
+	if spec.MinTrades == 0 {
+		spec.MinTrades = DefaultScanMinTrades
+	}
+
+	if spec.TopN == 0 {
+		spec.TopN = DefaultScanTopN
+	}
+
+	if spec.MinTrades < 0 || spec.TopN < 0 || spec.TopN > MaxScanTopN {
+		return errors.New("topN must be in the range 1.."+ strconv.Itoa(MaxScanTopN) +" and minTrades cannot be negative")
+	}
+
+	candidates := 7 * spd * spec.MaxSpan * len(spec.Operations) * len(spec.MonthMasks)
+	if candidates > MaxScanCandidates {
+		return errors.New("too many candidates ("+ strconv.Itoa(candidates) +"), the maximum is "+ strconv.Itoa(MaxScanCandidates))
+	}
+
+	return nil
+}
+
+//=============================================================================
+
+func getScanScore(btc *BacktestedConfig, metric string) float64 {
+	switch metric {
+		case ScanMetricAvgTrade:     return btc.NetAvgTrade
+		case ScanMetricProfitFactor: return btc.Metrics.ProfitFactor
+		case ScanMetricSharpe:       return btc.Metrics.SharpeRatio
+		case ScanMetricWinRate:      return btc.Metrics.WinRate
+	}
+
+	return btc.NetProfit
+}
+
+//=============================================================================
+//--- Must be called with the lock
+
+func getScanJob(c *auth.Context, id uint, jobId uint, function string) (*BiasScanJob, error) {
+	job, ok := scanJobs[jobId]
+	if !ok || job.BiasAnalysisId != id {
+		c.Log.Error(function +": Scan job was not found", "id", id, "jobId", jobId)
+		return nil, req.NewNotFoundError("Scan job was not found: %v", jobId)
+	}
+
+	if !c.Session.IsAdmin() && job.Username != c.Session.Username {
+		c.Log.Error(function +": Scan job not owned by user", "jobId", jobId)
+		return nil, req.NewForbiddenError("Scan job is not owned by user: %v", jobId)
+	}
+
+	return job, nil
+}
+
+//=============================================================================
+//--- Must be called with the lock
+
+func removeOldScanJobs() {
+	for id, job := range scanJobs {
+		if job.EndedAt != nil && time.Since(*job.EndedAt) > ScanJobRetention {
+			delete(scanJobs, id)
+		}
+	}
+}
+
+//=============================================================================
+//--- Must be called with the lock
+
+func countRunningScanJobs() int {
+	count := 0
+	for _, job := range scanJobs {
+		if job.Status == ScanStatusRunning {
+			count++
+		}
+	}
+
+	return count
+}
+
+//=============================================================================
+//--- Same as runBacktestedConfigs, but each bar is given only to the configs
+//--- that have an open trade or that can start one, as most candidates have
+//--- nothing to do on a given slot. Returns false if the job was cancelled
+
+func runScanBatch(configs []*BacktestedConfig, dataPoints []*ds.DataPoint, slotSize int, isCancelled func() bool) bool {
+	spd     := getSlotsPerDay(slotSize)
+	byStart := make([][]int, 7 * spd)
+
+	for k, btc := range configs {
+		key := int(btc.BiasConfig.StartDay) * spd + int(btc.BiasConfig.StartSlot)
+		byStart[key] = append(byStart[key], k)
+	}
+
+	lastBar := make([]int, len(configs))
+
+	var open, next []int
+
+	for i := 1; i < len(dataPoints); i++ {
+		if i % ScanCancelCheckBars == 0 && isCancelled() {
+			return false
+		}
+
+		dp     := dataPoints[i]
+		prevDp := dataPoints[i-1]
+		ti     := calcTimeInfo(dp, slotSize)
+		next    = next[:0]
+
+		for _, k := range open {
+			configs[k].RunBacktest(ti, dp, prevDp, i, dataPoints)
+			lastBar[k] = i
+
+			if configs[k].currTrade != nil {
+				next = append(next, k)
+			}
+		}
+
+		for _, k := range byStart[int(ti.dayOfWeek) * spd + int(ti.slot)] {
+			if lastBar[k] != i {
+				configs[k].RunBacktest(ti, dp, prevDp, i, dataPoints)
+				lastBar[k] = i
+
+				if configs[k].currTrade != nil {
+					next = append(next, k)
+				}
+			}
+		}
+
+		open, next = next, open
+	}
+
+	for _, btc := range configs {
+		btc.Finish()
+	}
+
+	return true
+}
+
+//=============================================================================
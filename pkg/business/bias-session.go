@@ -0,0 +1,175 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bit-fever/sick-engine/session"
+)
+
+//=============================================================================
+
+const MinutesPerWeek = 7 * 1440
+
+//=============================================================================
+//--- Session times are wall clock times in the product's timezone. Sessions
+//--- whose end is not after the start continue on the next day, and so do
+//--- pauses that begin before the session start
+
+type sessionCalendar struct {
+	loc    *time.Location
+	open   []*weekRange
+	pauses []*weekRange
+	ends   []int
+}
+
+//=============================================================================
+//--- Minutes from Sunday 00:00. The end can go past the end of the week
+
+type weekRange struct {
+	from int
+	to   int
+}
+
+//=============================================================================
+
+func newSessionCalendar(ts *session.TradingSession, loc *time.Location) *sessionCalendar {
+	if ts == nil || len(ts.Days) == 0 {
+		return nil
+	}
+
+	sc := &sessionCalendar{
+		loc: loc,
+	}
+
+	for _, sd := range ts.Days {
+		start := sd.Day * 1440 + sd.Start.Hour * 60 + sd.Start.Min
+		end   := sd.Day * 1440 + sd.End  .Hour * 60 + sd.End  .Min
+		if end <= start {
+			end += 1440
+		}
+
+		sc.open = append(sc.open, &weekRange{ from: start, to: end })
+		sc.ends = append(sc.ends, end % MinutesPerWeek)
+
+		for _, p := range sd.Pauses {
+			from := sd.Day * 1440 + p.From.Hour * 60 + p.From.Min
+			to   := sd.Day * 1440 + p.To  .Hour * 60 + p.To  .Min
+			if from < start {
+				from += 1440
+				to   += 1440
+			}
+			if to <= from {
+				to += 1440
+			}
+
+			sc.pauses = append(sc.pauses, &weekRange{ from: from, to: to })
+		}
+	}
+
+	return sc
+}
+
+//=============================================================================
+
+func (sc *sessionCalendar) isInside(t time.Time) bool {
+	m := minuteOfWeek(t.In(sc.loc))
+
+	return isInRanges(sc.open, m) && !isInRanges(sc.pauses, m)
+}
+
+//=============================================================================
+//--- Tells if a session ends in the (from, to] interval
+
+func (sc *sessionCalendar) hasEndIn(from, to time.Time) bool {
+	from = from.In(sc.loc)
+	to   = to  .In(sc.loc)
+
+	y,m,d     := from.Date()
+	weekStart := time.Date(y, m, d - int(from.Weekday()), 0, 0, 0, 0, sc.loc)
+
+	for ; !weekStart.After(to); weekStart = weekStart.AddDate(0, 0, 7) {
+		for _, end := range sc.ends {
+			t := time.Date(weekStart.Year(), weekStart.Month(), weekStart.Day() + end / 1440, end % 1440 / 60, end % 60, 0, 0, sc.loc)
+
+			if t.After(from) && !t.After(to) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func checkSession(ts *session.TradingSession) error {
+	for _, sd := range ts.Days {
+		if sd.Day < 0 || sd.Day > 6 {
+			return errors.New("session day must be in the range 0..6")
+		}
+
+		if sd.Start == nil || sd.End == nil {
+			return errors.New("session days must have start and end")
+		}
+
+		for _, p := range sd.Pauses {
+			if p.From == nil || p.To == nil {
+				return errors.New("session pauses must have from and to")
+			}
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
+
+func minuteOfWeek(t time.Time) int {
+	h,m,_ := t.Clock()
+	return int(t.Weekday()) * 1440 + h * 60 + m
+}
+
+//=============================================================================
+//--- Ranges can cross the end of the week, so the minute is also checked in
+//--- the following week
+
+func isInRanges(ranges []*weekRange, m int) bool {
+	for _, r := range ranges {
+		if (m >= r.from && m < r.to) || (m + MinutesPerWeek >= r.from && m + MinutesPerWeek < r.to) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//=============================================================================
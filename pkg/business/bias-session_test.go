@@ -0,0 +1,143 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bit-fever/sick-engine/core"
+	"github.com/bit-fever/sick-engine/session"
+)
+
+//=============================================================================
+
+var chicago,_ = time.LoadLocation("America/Chicago")
+
+func chi(y int, m time.Month, d, hour, min int) time.Time {
+	return time.Date(y, m, d, hour, min, 0, 0, chicago)
+}
+
+//-----------------------------------------------------------------------------
+//--- Sunday to Thursday, from 17:00 to 16:00 of the next day. The Monday session
+//--- has a pause on Tuesday morning. DST starts on Sunday 2024-03-10
+
+func newGlobexSession() *session.TradingSession {
+	ts := &session.TradingSession{}
+
+	for day := 0; day <= 4; day++ {
+		sd := &session.SessionDay{
+			Day  : day,
+			Start: &core.Time{ Hour: 17 },
+			End  : &core.Time{ Hour: 16 },
+		}
+
+		if day == 1 {
+			sd.Pauses = []*session.Pause{
+				{ From: &core.Time{ Hour: 8, Min: 15 }, To: &core.Time{ Hour: 8, Min: 30 } },
+			}
+		}
+
+		ts.Days = append(ts.Days, sd)
+	}
+
+	return ts
+}
+
+//-----------------------------------------------------------------------------
+//--- Saturday from 20:00 to 01:00, crossing the end of the week
+
+var saturdaySession = &session.TradingSession{
+	Days: []*session.SessionDay{
+		{ Day: 6, Start: &core.Time{ Hour: 20 }, End: &core.Time{ Hour: 1 } },
+	},
+}
+
+//=============================================================================
+
+func TestSessionCalendarIsInside(t *testing.T) {
+	globex   := newSessionCalendar(newGlobexSession(), chicago)
+	saturday := newSessionCalendar(saturdaySession,    chicago)
+
+	tests := []struct {
+		name     string
+		calendar *sessionCalendar
+		time     time.Time
+		inside   bool
+	}{
+		{"Sunday open (DST day)",        globex,   chi(2024,3,10, 17,30), true },
+		{"Sunday before the open",       globex,   chi(2024,3,10, 16,59), false},
+		{"Saturday",                     globex,   chi(2024,3, 9, 12, 0), false},
+		{"Overnight into Wednesday",     globex,   chi(2024,3,13,  2, 0), true },
+		{"Daily break",                  globex,   chi(2024,3,11, 16,30), false},
+		{"Friday before the close",      globex,   chi(2024,3,15, 15,59), true },
+		{"Friday close",                 globex,   chi(2024,3,15, 16, 0), false},
+		{"Pause on Tuesday morning",     globex,   chi(2024,3,12,  8,20), false},
+		{"End of the pause",             globex,   chi(2024,3,12,  8,30), true },
+		{"No pause on Wednesday",        globex,   chi(2024,3,13,  8,20), true },
+		{"Saturday evening",             saturday, chi(2024,3, 9, 21, 0), true },
+		{"Sunday after the week end",    saturday, chi(2024,3,10,  0,30), true },
+		{"Sunday at the close",          saturday, chi(2024,3,10,  1, 0), false},
+	}
+
+	for _, tt := range tests {
+		if inside := tt.calendar.isInside(tt.time); inside != tt.inside {
+			t.Errorf("%v: expected inside=%v but got %v", tt.name, tt.inside, inside)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestSessionCalendarHasEndIn(t *testing.T) {
+	globex   := newSessionCalendar(newGlobexSession(), chicago)
+	saturday := newSessionCalendar(saturdaySession,    chicago)
+
+	tests := []struct {
+		name     string
+		calendar *sessionCalendar
+		from     time.Time
+		to       time.Time
+		found    bool
+	}{
+		{"Friday close is included",     globex,   chi(2024,3,15, 15,30), chi(2024,3,15, 16, 0), true },
+		{"Friday close is excluded",     globex,   chi(2024,3,15, 16, 0), chi(2024,3,15, 16,30), false},
+		{"Monday close",                 globex,   chi(2024,3,11, 15,45), chi(2024,3,11, 16,15), true },
+		{"Weekend up to Sunday open",    globex,   chi(2024,3, 9, 10, 0), chi(2024,3,10, 18, 0), false},
+		{"Across the DST change",        globex,   chi(2024,3, 9, 12, 0), chi(2024,3,11, 16, 0), true },
+		{"Inside a session",             globex,   chi(2024,3,12, 17, 0), chi(2024,3,13, 15,59), false},
+		{"Pauses are not ends",          globex,   chi(2024,3,12,  8, 0), chi(2024,3,12,  8,45), false},
+		{"Close after the week end",     saturday, chi(2024,3, 9, 23, 0), chi(2024,3,10,  1, 0), true },
+		{"Before the Saturday session",  saturday, chi(2024,3, 9, 12, 0), chi(2024,3, 9, 23, 0), false},
+	}
+
+	for _, tt := range tests {
+		if found := tt.calendar.hasEndIn(tt.from, tt.to); found != tt.found {
+			t.Errorf("%v: expected %v but got %v", tt.name, tt.found, found)
+		}
+	}
+}
+
+//=============================================================================
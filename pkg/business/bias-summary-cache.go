@@ -0,0 +1,378 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bit-fever/core/datatype"
+	"github.com/bit-fever/data-collector/pkg/db"
+	"github.com/bit-fever/data-collector/pkg/ds"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+//--- Summaries hold all data points of their analysis, so only the most recently
+//--- used ones are kept
+const MaxCachedSummaries = 32
+
+//=============================================================================
+//--- Tells if the data used by a summary has changed. New days only move DataTo
+//--- forward, while rollovers and uploads can change the whole history
+
+type dataVersion struct {
+	DataInstrumentId uint
+	DataTo           datatype.IntDate
+	RolloverId       uint
+	IngestionId      uint
+}
+
+//=============================================================================
+//--- Cached summaries are never modified: an incremental refresh copies only
+//--- the slots it touches, so responses already returned stay consistent.
+//--- lastUsed is the only exception and is changed with the lock
+
+type summaryCacheEntry struct {
+	version   dataVersion
+	slotSize  int
+	prevDp    *ds.DataPoint
+	lastDelta *DataPointDelta
+	result    [7]*DataPointDowList
+	lastUsed  time.Time
+}
+
+//-----------------------------------------------------------------------------
+
+func (e *summaryCacheEntry) isValidFor(bsr *BiasSummaryResponse) bool {
+	return bsr.version != nil && e.version == *bsr.version && e.slotSize == bsr.SlotSize
+}
+
+//-----------------------------------------------------------------------------
+
+func (e *summaryCacheEntry) canBeExtendedTo(bsr *BiasSummaryResponse) bool {
+	if bsr.version == nil || e.lastDelta == nil || e.slotSize != bsr.SlotSize {
+		return false
+	}
+
+	v := bsr.version
+
+	return e.version.DataInstrumentId == v.DataInstrumentId &&
+		e.version.RolloverId  == v.RolloverId  &&
+		e.version.IngestionId == v.IngestionId &&
+		e.version.DataTo       < v.DataTo
+}
+
+//=============================================================================
+
+var summaryCache = map[uint]*summaryCacheEntry{}
+var summaryCacheMutex sync.Mutex
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func getCachedSummary(id uint) *summaryCacheEntry {
+	summaryCacheMutex.Lock()
+	defer summaryCacheMutex.Unlock()
+
+	entry := summaryCache[id]
+	if entry != nil {
+		entry.lastUsed = time.Now()
+	}
+
+	return entry
+}
+
+//=============================================================================
+//--- Synthetic instruments have no data version, so they are not cached. When
+//--- the cache is full, the least recently used summary is removed
+
+func setCachedSummary(id uint, entry *summaryCacheEntry) {
+	if entry.version.DataInstrumentId == 0 {
+		return
+	}
+
+	summaryCacheMutex.Lock()
+	defer summaryCacheMutex.Unlock()
+
+	if _, found := summaryCache[id]; !found && len(summaryCache) >= MaxCachedSummaries {
+		var oldestId uint
+		var oldest  *summaryCacheEntry
+
+		for cid, ce := range summaryCache {
+			if oldest == nil || ce.lastUsed.Before(oldest.lastUsed) {
+				oldestId = cid
+				oldest   = ce
+			}
+		}
+
+		delete(summaryCache, oldestId)
+	}
+
+	entry.lastUsed   = time.Now()
+	summaryCache[id] = entry
+}
+
+//=============================================================================
+
+func removeCachedSummary(id uint) {
+	summaryCacheMutex.Lock()
+	defer summaryCacheMutex.Unlock()
+
+	delete(summaryCache, id)
+}
+
+//=============================================================================
+//--- Virtual instruments depend on the blocks of all their rolling instruments
+
+func getDataVersion(tx *gorm.DB, diId uint, config *DataConfig) (*dataVersion, error) {
+	if config.Synthetic != nil {
+		return nil, nil
+	}
+
+	di, err := db.GetDataInstrumentById(tx, diId)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []uint{ diId }
+	if config.Instruments != nil {
+		for _, i := range *config.Instruments {
+			ids = append(ids, i.Id)
+		}
+	}
+
+	var dataTo datatype.IntDate
+	dataTo, err = db.GetLatestDataToByInstrumentIds(tx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var rolloverId uint
+	rolloverId, err = db.GetLatestRolloverHistoryIdByProductId(tx, di.DataProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	var ingestionId uint
+	ingestionId, err = db.GetLatestIngestionJobIdByInstrumentIds(tx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dataVersion{
+		DataInstrumentId: diId,
+		DataTo          : dataTo,
+		RolloverId      : rolloverId,
+		IngestionId     : ingestionId,
+	}, nil
+}
+
+//=============================================================================
+//--- When extending, the last bar of the cached summary could have been partial,
+//--- so its delta is removed and the bar is loaded again
+
+func calcSummary(bsr *BiasSummaryResponse, base *summaryCacheEntry) (*summaryCacheEntry, error) {
+	loc,_ := time.LoadLocation(bsr.config.Timezone)
+	da    := newSlotAggregator(bsr.config, bsr.SlotSize, loc)
+	params:= newDefaultDataParams(loc, da)
+	sb    := newSummaryBuilder(bsr.SlotSize)
+
+	var prevDp *ds.DataPoint
+
+	if base != nil {
+		sb.result   = base.result
+		sb.remove(base.lastDelta)
+		prevDp      = base.prevDp
+		params.From = prevDp.Time.Add(time.Second)
+	}
+
+	dataPoints, err := getDataPoints(params, bsr.config)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &summaryCacheEntry{
+		slotSize: bsr.SlotSize,
+	}
+
+	if bsr.version != nil {
+		entry.version = *bsr.version
+	}
+
+	for _, dpCurr := range dataPoints {
+		if prevDp != nil {
+			dpDelta := newDataPointDelta(prevDp, dpCurr, bsr.SlotSize)
+			sb.add(dpDelta)
+
+			entry.prevDp    = prevDp
+			entry.lastDelta = dpDelta
+		}
+
+		prevDp = dpCurr
+	}
+
+	//--- No new bars: the old delta must be restored
+
+	if base != nil && entry.lastDelta == nil {
+		sb.add(base.lastDelta)
+		entry.prevDp    = base.prevDp
+		entry.lastDelta = base.lastDelta
+	}
+
+	sb.calcStats()
+	entry.result = sb.result
+
+	return entry, nil
+}
+
+//=============================================================================
+
+func compactResult(result [7]*DataPointDowList) [7]*DataPointDowList {
+	var compact [7]*DataPointDowList
+
+	for dow, dpdl := range result {
+		if dpdl != nil {
+			compact[dow] = &DataPointDowList{
+				Slots: make([]*DataPointSlotList, len(dpdl.Slots)),
+			}
+
+			for slot, dpsl := range dpdl.Slots {
+				if dpsl != nil {
+					compact[dow].Slots[slot] = &DataPointSlotList{
+						Stats: dpsl.Stats,
+					}
+				}
+			}
+		}
+	}
+
+	return compact
+}
+
+//=============================================================================
+//===
+//=== summaryBuilder
+//===
+//=============================================================================
+//--- Lists are copied before the first change, so lists shared with a cached
+//--- summary are never modified
+
+type summaryBuilder struct {
+	result   [7]*DataPointDowList
+	slotSize int
+	owned    map[any]bool
+}
+
+//=============================================================================
+
+func newSummaryBuilder(slotSize int) *summaryBuilder {
+	return &summaryBuilder{
+		slotSize: slotSize,
+		owned   : map[any]bool{},
+	}
+}
+
+//=============================================================================
+
+func (sb *summaryBuilder) add(dpd *DataPointDelta) {
+	sb.getSlotList(dpd).Add(dpd)
+}
+
+//=============================================================================
+
+func (sb *summaryBuilder) remove(dpd *DataPointDelta) {
+	dpsl := sb.getSlotList(dpd)
+	if len(dpsl.List) > 0 {
+		dpsl.List = dpsl.List[:len(dpsl.List) -1]
+	}
+}
+
+//=============================================================================
+//--- Bootstrapping is expensive, so days are processed in parallel and only
+//--- the changed slots are recalculated
+
+func (sb *summaryBuilder) calcStats() {
+	var wg sync.WaitGroup
+
+	for _, dpdl := range sb.result {
+		if dpdl != nil && sb.owned[dpdl] {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for _, dpsl := range dpdl.Slots {
+					if dpsl != nil && sb.owned[dpsl] {
+						dpsl.calcStats()
+					}
+				}
+			}()
+		}
+	}
+
+	wg.Wait()
+}
+
+//=============================================================================
+
+func (sb *summaryBuilder) getSlotList(dpd *DataPointDelta) *DataPointSlotList {
+	dpdl := sb.result[dpd.Dow]
+	if dpdl == nil || !sb.owned[dpdl] {
+		newDpdl := &DataPointDowList{
+			Slots: make([]*DataPointSlotList, getSlotsPerDay(sb.slotSize)),
+		}
+
+		if dpdl != nil {
+			copy(newDpdl.Slots, dpdl.Slots)
+		}
+
+		dpdl = newDpdl
+		sb.result[dpd.Dow] = dpdl
+		sb.owned [dpdl]    = true
+	}
+
+	slot := getSlot(dpd.Hour, dpd.Min, sb.slotSize)
+	dpsl := dpdl.Slots[slot]
+	if dpsl == nil || !sb.owned[dpsl] {
+		newDpsl := &DataPointSlotList{
+			List: []*DataPointEntry{},
+		}
+
+		if dpsl != nil {
+			newDpsl.List = append(newDpsl.List, dpsl.List...)
+		}
+
+		dpsl = newDpsl
+		dpdl.Slots[slot] = dpsl
+		sb.owned[dpsl]   = true
+	}
+
+	return dpsl
+}
+
+//=============================================================================
@@ -35,54 +35,30 @@ import (
 
 //=============================================================================
 
+const DefaultSlotSize = 30
+
+//=============================================================================
+
 type BiasSummaryResponse struct {
 	BiasAnalysis  *db.BiasAnalysis     `json:"biasAnalysis"`
 	BrokerProduct *db.BrokerProduct    `json:"brokerProduct"`
+	SlotSize      int                  `json:"slotSize"`
 	Result        [7]*DataPointDowList `json:"result"`
 	config        *DataConfig
-}
-
-//-----------------------------------------------------------------------------
-
-func (r *BiasSummaryResponse) Add(dpd *DataPointDelta) {
-	dpdl := r.Result[dpd.Dow]
-	if dpdl == nil {
-		dpdl = &DataPointDowList{
-			Slots: [48]*DataPointSlotList{},
-		}
-
-		r.Result[dpd.Dow] = dpdl
-	}
-
-	dpdl.Add(dpd)
+	version       *dataVersion
 }
 
 //=============================================================================
 
 type DataPointDowList struct {
-	Slots [48]*DataPointSlotList `json:"slots"`
-}
-
-//-----------------------------------------------------------------------------
-
-func (l *DataPointDowList) Add(dpd *DataPointDelta) {
-	slot := (dpd.Hour * 60 + dpd.Min) / 30
-	dpsl := l.Slots[slot]
-	if dpsl == nil {
-		dpsl = &DataPointSlotList{
-			List: []*DataPointEntry{},
-		}
-
-		l.Slots[slot] = dpsl
-	}
-
-	l.Slots[slot].Add(dpd)
+	Slots []*DataPointSlotList `json:"slots"`
 }
 
 //=============================================================================
 
 type DataPointSlotList struct {
-	List []*DataPointEntry `json:"list"`
+	List  []*DataPointEntry `json:"list,omitempty"`
+	Stats *SlotStats        `json:"stats,omitempty"`
 }
 
 //-----------------------------------------------------------------------------
@@ -98,6 +74,17 @@ func (l *DataPointSlotList) Add(dpd *DataPointDelta) {
 	l.List = append(l.List, dpe)
 }
 
+//-----------------------------------------------------------------------------
+
+func (l *DataPointSlotList) calcStats() {
+	deltas := make([]float64, len(l.List))
+	for i, dpe := range l.List {
+		deltas[i] = dpe.Delta
+	}
+
+	l.Stats = NewSlotStats(deltas)
+}
+
 //=============================================================================
 
 type DataPointEntry struct {
@@ -123,6 +110,13 @@ func GetBiasSummaryInfo(tx *gorm.DB, c *auth.Context, id uint) (*BiasSummaryResp
 		return nil, err
 	}
 
+	var version *dataVersion
+	version, err = getDataVersion(tx, ba.DataInstrumentId, config)
+	if err != nil {
+		c.Log.Error("GetBiasSummaryInfo: Could not retrieve data version", "error", err.Error())
+		return nil, err
+	}
+
 	var bp *db.BrokerProduct
 	bp, err = db.GetBrokerProductById(tx, ba.BrokerProductId)
 	if err != nil {
@@ -135,32 +129,41 @@ func GetBiasSummaryInfo(tx *gorm.DB, c *auth.Context, id uint) (*BiasSummaryResp
 	return &BiasSummaryResponse{
 		BiasAnalysis : ba,
 		BrokerProduct: bp,
+		SlotSize     : getSlotSize(ba),
 		Result       : [7]*DataPointDowList{},
 		config       : config,
+		version      : version,
 	}, nil
 }
 
 //=============================================================================
+//--- The summary is taken from the cache if the data has not changed, or it is
+//--- extended with the new bars if only new days arrived. In compact mode, only
+//--- the statistics are returned
 
-func GetBiasSummaryData(c *auth.Context, id uint, bsr *BiasSummaryResponse) error {
-	bsr.config.DataConfig.Timeframe = "15m"
-
-	loc,_:= time.LoadLocation(bsr.config.Timezone)
-	da   := ds.NewDataAggregator(ds.TimeSlotFunction30m, loc)
-	err  := ds.GetDataPoints(DefaultFrom, DefaultTo, &bsr.config.DataConfig, loc, da)
+func GetBiasSummaryData(c *auth.Context, id uint, bsr *BiasSummaryResponse, compact bool) error {
+	entry := getCachedSummary(id)
 
-	if err != nil {
-		return err
-	}
+	if entry == nil || !entry.isValidFor(bsr) {
+		var base *summaryCacheEntry
+		if entry != nil && entry.canBeExtendedTo(bsr) {
+			base = entry
+		}
 
-	dataPoints := da.DataPoints()
+		c.Log.Info("GetBiasSummaryData: Calculating summary", "id", id, "incremental", base != nil)
 
-	for i, dpCurr := range dataPoints {
-		if i>0 {
-			dpPrev  := dataPoints[i -1]
-			dpDelta := newDataPointDelta(dpPrev, dpCurr)
-			bsr.Add(dpDelta)
+		var err error
+		entry, err = calcSummary(bsr, base)
+		if err != nil {
+			return err
 		}
+
+		setCachedSummary(id, entry)
+	}
+
+	bsr.Result = entry.result
+	if compact {
+		bsr.Result = compactResult(entry.result)
 	}
 
 	return nil
@@ -172,13 +175,10 @@ func GetBiasSummaryData(c *auth.Context, id uint, bsr *BiasSummaryResponse) erro
 //===
 //=============================================================================
 
-func newDataPointDelta(dpPrev, dpCurr *ds.DataPoint) *DataPointDelta {
+func newDataPointDelta(dpPrev, dpCurr *ds.DataPoint, slotSize int) *DataPointDelta {
 	delta := dpCurr.Close - dpPrev.Close
 
-	//--- Calc slot time from destination to take into account leaps when markets
-	//--- are closed (i.e. slot 16:00 - 17:30 will have 16:00 instead of 17:00)
-
-	slotTime := dpCurr.Time.Add(-time.Minute * 30)
+	slotTime := getSlotTime(dpCurr, slotSize)
 
 	y,m,d := slotTime.Date()
 	hour  := slotTime.Hour()
@@ -196,6 +196,65 @@ func newDataPointDelta(dpPrev, dpCurr *ds.DataPoint) *DataPointDelta {
 	}
 }
 
+//=============================================================================
+//--- Calc slot time from destination to take into account leaps when markets
+//--- are closed (i.e. slot 16:00 - 17:30 will have 16:00 instead of 17:00)
+
+func getSlotTime(dp *ds.DataPoint, slotSize int) time.Time {
+	return dp.Time.Add(-time.Minute * time.Duration(slotSize))
+}
+
+//=============================================================================
+//--- Returns the index of the slot in the day
+
+func getSlot(hour, mins int, slotSize int) int {
+	return (hour * 60 + mins) / slotSize
+}
+
+//=============================================================================
+//--- Analyses created before slot sizes were introduced have 0, that is 30m
+
+func getSlotSize(ba *db.BiasAnalysis) int {
+	if ba.SlotSize == 0 {
+		return DefaultSlotSize
+	}
+
+	return int(ba.SlotSize)
+}
+
+//=============================================================================
+
+func getSlotsPerDay(slotSize int) int {
+	return 1440 / slotSize
+}
+
+//=============================================================================
+
+func isValidSlotSize(slotSize int16) bool {
+	return slotSize == 15 || slotSize == 30 || slotSize == 60 || slotSize == 120
+}
+
+//=============================================================================
+//--- Sets the timeframe to query and returns an aggregator that builds the
+//--- slot bars from it
+
+func newSlotAggregator(config *DataConfig, slotSize int, loc *time.Location) *ds.DataAggregator {
+	switch slotSize {
+		case 15:
+			config.DataConfig.Timeframe = "15m"
+			return ds.NewDataAggregator(nil, loc)
+		case 60:
+			config.DataConfig.Timeframe = "60m"
+			return ds.NewDataAggregator(nil, loc)
+		case 120:
+			config.DataConfig.Timeframe = "60m"
+			return ds.NewDataAggregator(ds.TimeSlotFunction120m, loc)
+	}
+
+	config.DataConfig.Timeframe = "15m"
+	return ds.NewDataAggregator(ds.TimeSlotFunction30m, loc)
+}
+
 //=============================================================================
 
 type DataPointDelta struct {
@@ -26,7 +26,6 @@ package business
 
 import (
 	"github.com/bit-fever/data-collector/pkg/core"
-	"github.com/bit-fever/data-collector/pkg/db"
 	"github.com/bit-fever/data-collector/pkg/ds"
 	"strconv"
 	"time"
@@ -39,9 +38,13 @@ import (
 //=============================================================================
 
 const (
-	ExitConditionNormal    =  0
-	ExitConditionStop      = -1
-	ExitConditionProfit = +1
+	ExitConditionNormal       =  0
+	ExitConditionStop         = -1
+	ExitConditionProfit       = +1
+	ExitConditionTrailingStop = -2
+	ExitConditionBreakeven    = -3
+	ExitConditionMaxTime      = +2
+	ExitConditionEndOfSession = +3
 )
 
 //-----------------------------------------------------------------------------
@@ -55,15 +58,23 @@ type BiasTrade struct {
 	GrossProfit   float64   `json:"grossProfit"`
 	NetProfit     float64   `json:"netProfit"`
 	ExitCondition int8      `json:"exitCondition"`
+	Commission    float64   `json:"commission"`
+	Slippage      float64   `json:"slippage"`
+	FxRate        float64   `json:"fxRate,omitempty"`
 
 	stopValue     float64
 	profitValue   float64
+	stopCondition int8
+	trailingDelta float64
+	breakeven     float64
+	slots         int
+	entrySlippage float64
 }
 
 //=============================================================================
 
-func NewBiasTrade(currDp, prevDp *ds.DataPoint, btc *BacktestedConfig) *BiasTrade {
-	entryValue  := prevDp.Close
+func NewBiasTrade(currDp, prevDp *ds.DataPoint, btc *BacktestedConfig, index int) *BiasTrade {
+	entryValue  := btc.getEntryValue(currDp, prevDp)
 	stopValue   := 0.0
 	profitValue := 0.0
 
@@ -91,13 +102,22 @@ func NewBiasTrade(currDp, prevDp *ds.DataPoint, btc *BacktestedConfig) *BiasTrad
 	}
 
 	bt := &BiasTrade{
-		EntryTime  : currDp.Time.Add(-time.Minute * 30),
-		EntryValue : entryValue,
-		Operation  : btc.BiasConfig.Operation,
-		stopValue  : stopValue,
-		profitValue: profitValue,
+		EntryTime    : getSlotTime(currDp, btc.slotSize),
+		EntryValue   : entryValue,
+		Operation    : btc.BiasConfig.Operation,
+		stopValue    : stopValue,
+		profitValue  : profitValue,
+		stopCondition: ExitConditionStop,
+		trailingDelta: btc.getTrailingDelta(index),
+		entrySlippage: btc.costs.marketSlippage(currDp),
 	}
 
+	if btc.spec.BreakevenAfter > 0 {
+		bt.breakeven = bt.offsetValue(btc.spec.BreakevenAfter / float64(btc.brokerProduct.PointValue))
+	}
+
+	bt.updateTrailingStop(entryValue, entryValue)
+
 	return bt
 }
 
@@ -133,27 +153,126 @@ func (bt *BiasTrade) IsInProfit(currDp *ds.DataPoint) bool {
 
 //=============================================================================
 
-func (bt *BiasTrade) Close(dp *ds.DataPoint, bp *db.BrokerProduct, exitCondition int8) {
+//--- Moves the stop after the checks on the bar, as the order of high and low
+//--- inside the bar is unknown
+
+func (bt *BiasTrade) UpdateStop(currDp *ds.DataPoint) {
+	if bt.breakeven != 0 && bt.isTighterStop(bt.EntryValue) {
+		if bt.isFavourable(currDp, bt.breakeven) {
+			bt.stopValue     = bt.EntryValue
+			bt.stopCondition = ExitConditionBreakeven
+		}
+	}
+
+	bt.updateTrailingStop(currDp.High, currDp.Low)
+}
+
+//=============================================================================
+
+//--- GrossProfit is on the order levels, while slippage and commissions of both
+//--- sides are kept separated and subtracted from NetProfit
+
+func (bt *BiasTrade) Close(dp *ds.DataPoint, cm *costModel, exitCondition int8) {
 	var exitValue float64
 
 	switch exitCondition {
-		case ExitConditionNormal: exitValue = dp.Close
-		case ExitConditionStop:   exitValue = bt.stopValue
-		case ExitConditionProfit: exitValue = bt.profitValue
+		case ExitConditionStop, ExitConditionTrailingStop, ExitConditionBreakeven:
+			exitValue = bt.stopValue
+		case ExitConditionProfit:
+			exitValue = bt.profitValue
+		default:
+			exitValue = dp.Close
 	}
 
-	bt.ExitTime   = dp.Time
-	bt.ExitValue  = exitValue
+	bt.ExitTime      = dp.Time
+	bt.ExitValue     = exitValue
+	bt.ExitCondition = exitCondition
 
-	bt.GrossProfit = (bt.ExitValue - bt.EntryValue) * float64(bp.PointValue)
+	bt.GrossProfit = (bt.ExitValue - bt.EntryValue) * cm.pointValue
 
 	if bt.Operation == 1 {
 		bt.GrossProfit *= -1
 	}
 
 	//--- We have 2 trades: 1 to enter and 1 to exit the market
-	bt.NetProfit   = core.Trunc2d(bt.GrossProfit - 2 * float64(bp.CostPerOperation))
+	bt.Commission  = core.Trunc2d(2 * cm.commission)
+	bt.Slippage    = core.Trunc2d((bt.entrySlippage + cm.exitSlippage(dp, exitCondition)) * cm.pointValue)
+	bt.NetProfit   = core.Trunc2d(bt.GrossProfit - bt.Commission - bt.Slippage)
 	bt.GrossProfit = core.Trunc2d(bt.GrossProfit)
 }
 
 //=============================================================================
+
+func (bt *BiasTrade) ConvertProfit(rate float64) {
+	bt.FxRate      = rate
+	bt.Commission  = core.Trunc2d(bt.Commission  * rate)
+	bt.Slippage    = core.Trunc2d(bt.Slippage    * rate)
+	bt.GrossProfit = core.Trunc2d(bt.GrossProfit * rate)
+	bt.NetProfit   = core.Trunc2d(bt.NetProfit   * rate)
+}
+
+//=============================================================================
+//===
+//=== Private methods
+//===
+//=============================================================================
+
+func (bt *BiasTrade) updateTrailingStop(high, low float64) {
+	if bt.trailingDelta == 0 {
+		return
+	}
+
+	var value float64
+
+	switch bt.Operation {
+		case 0: value = high - bt.trailingDelta
+		case 1: value = low  + bt.trailingDelta
+
+		default: panic("Unknown trade operation: "+ strconv.Itoa(int(bt.Operation)))
+	}
+
+	if bt.isTighterStop(value) {
+		bt.stopValue     = value
+		bt.stopCondition = ExitConditionTrailingStop
+	}
+}
+
+//=============================================================================
+//--- Tells if a stop at value would be tighter than the current one
+
+func (bt *BiasTrade) isTighterStop(value float64) bool {
+	if bt.stopValue == 0 {
+		return true
+	}
+
+	switch bt.Operation {
+		case 0: return value > bt.stopValue
+		case 1: return value < bt.stopValue
+
+		default: panic("Unknown trade operation: "+ strconv.Itoa(int(bt.Operation)))
+	}
+}
+
+//=============================================================================
+
+func (bt *BiasTrade) isFavourable(currDp *ds.DataPoint, value float64) bool {
+	switch bt.Operation {
+		case 0: return currDp.High >= value
+		case 1: return currDp.Low  <= value
+
+		default: panic("Unknown trade operation: "+ strconv.Itoa(int(bt.Operation)))
+	}
+}
+
+//=============================================================================
+//--- Returns the value at the given distance in the direction of the profit
+
+func (bt *BiasTrade) offsetValue(delta float64) float64 {
+	if bt.Operation == 1 {
+		return bt.EntryValue - delta
+	}
+
+	return bt.EntryValue + delta
+}
+
+//=============================================================================
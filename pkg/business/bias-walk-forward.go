@@ -0,0 +1,365 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/data-collector/pkg/db"
+	"github.com/bit-fever/data-collector/pkg/ds"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+const (
+	MaxTrainMonths = 240
+	MaxTestMonths  = 120
+)
+
+//=============================================================================
+//===
+//=== Structures
+//===
+//=============================================================================
+
+type WalkForwardSpec struct {
+	Backtest    BiasBacktestSpec `json:"backtest"`
+	TrainMonths int              `json:"trainMonths"`
+	TestMonths  int              `json:"testMonths"`
+}
+
+//=============================================================================
+
+type WalkForwardResponse struct {
+	BiasAnalysis  *db.BiasAnalysis     `json:"biasAnalysis"`
+	BrokerProduct *db.BrokerProduct    `json:"brokerProduct"`
+	Spec          *WalkForwardSpec     `json:"spec"`
+	Configs       []*WalkForwardConfig `json:"configs"`
+
+	backtest      *BiasBacktestResponse
+}
+
+//=============================================================================
+//--- Result holds the stitched out-of-sample trades of all test windows
+
+type WalkForwardConfig struct {
+	BiasConfig *BiasConfig          `json:"biasConfig"`
+	Windows    []*WalkForwardWindow `json:"windows"`
+	Trades     []*BiasTrade         `json:"biasTrades"`
+	Result     *SampleResult        `json:"result"`
+}
+
+//=============================================================================
+//--- Slots chosen on the training window and traded on the test one. A window
+//--- is skipped when no slot range had a positive average in training
+
+type WalkForwardWindow struct {
+	TrainFrom time.Time `json:"trainFrom"`
+	TestFrom  time.Time `json:"testFrom"`
+	TestTo    time.Time `json:"testTo"`
+	StartDay  int16     `json:"startDay"`
+	StartSlot int16     `json:"startSlot"`
+	EndDay    int16     `json:"endDay"`
+	EndSlot   int16     `json:"endSlot"`
+	Skipped   bool      `json:"skipped"`
+	NumTrades int       `json:"numTrades"`
+	NetProfit float64   `json:"netProfit"`
+}
+
+//=============================================================================
+
+type walkForwardRange struct {
+	trainFrom  time.Time
+	testFrom   time.Time
+	testTo     time.Time
+	trainStart int
+	testStart  int
+	testEnd    int
+}
+
+//=============================================================================
+//===
+//=== Functions
+//===
+//=============================================================================
+
+func GetWalkForwardInfo(tx *gorm.DB, c *auth.Context, id uint, spec *WalkForwardSpec) (*WalkForwardResponse, error) {
+	if spec.TrainMonths < 1 || spec.TrainMonths > MaxTrainMonths {
+		c.Log.Error("GetWalkForwardInfo: Invalid training months", "trainMonths", spec.TrainMonths)
+		return nil, req.NewBadRequestError("Training months must be in the range 1..%v: %v", MaxTrainMonths, spec.TrainMonths)
+	}
+
+	if spec.TestMonths < 1 || spec.TestMonths > MaxTestMonths {
+		c.Log.Error("GetWalkForwardInfo: Invalid test months", "testMonths", spec.TestMonths)
+		return nil, req.NewBadRequestError("Test months must be in the range 1..%v: %v", MaxTestMonths, spec.TestMonths)
+	}
+
+	bbr, err := GetBacktestInfo(tx, c, id, &spec.Backtest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WalkForwardResponse{
+		BiasAnalysis : bbr.BiasAnalysis,
+		BrokerProduct: bbr.BrokerProduct,
+		Spec         : spec,
+		Configs      : []*WalkForwardConfig{},
+		backtest     : bbr,
+	}, nil
+}
+
+//=============================================================================
+//--- Trades still open at the end of a test window are discarded
+
+func RunWalkForward(c *auth.Context, wfr *WalkForwardResponse) error {
+	c.Log.Info("RunWalkForward: Starting walk-forward for bias analysis", "id", wfr.BiasAnalysis.Id)
+
+	bbr      := wfr.backtest
+	slotSize := getSlotSize(bbr.BiasAnalysis)
+
+	dataPoints, fxc, err := getBacktestData(c.Log, bbr, slotSize)
+	if err != nil {
+		return err
+	}
+
+	loc,_ := time.LoadLocation(bbr.config.Timezone)
+	ranges:= buildWalkForwardRanges(dataPoints, wfr.Spec, slotSize, loc)
+	ms    := newMinuteSource(bbr)
+
+	//--- ATR is computed on the whole history, so that it is ready at the start
+	//--- of each test window
+	atr := prepareBacktestedConfigs(bbr.BacktestedConfigs, dataPoints, nil)
+
+	for _, btc := range bbr.BacktestedConfigs {
+		wfc := &WalkForwardConfig{
+			BiasConfig: btc.BiasConfig,
+			Windows   : []*WalkForwardWindow{},
+			Trades    : []*BiasTrade{},
+		}
+
+		for _, wr := range ranges {
+			wfw, err := wfc.runWindow(btc, wr, dataPoints, slotSize, fxc, ms, atr)
+			if err != nil {
+				c.Log.Error("RunWalkForward: Could not build backtested config", "error", err.Error())
+				return err
+			}
+
+			wfc.Windows = append(wfc.Windows, wfw)
+		}
+
+		wfc.Result = NewSampleResult(wfc.Trades)
+		wfr.Configs = append(wfr.Configs, wfc)
+	}
+
+	if ms != nil && ms.err != nil {
+		c.Log.Error("RunWalkForward: Could not retrieve 1m data points", "error", ms.err.Error())
+		return ms.err
+	}
+
+	c.Log.Info("RunWalkForward: Walk-forward completed", "id", wfr.BiasAnalysis.Id, "windows", len(ranges))
+	return nil
+}
+
+//=============================================================================
+//===
+//=== Private methods
+//===
+//=============================================================================
+
+func (wfc *WalkForwardConfig) runWindow(btc *BacktestedConfig, wr *walkForwardRange, dataPoints []*ds.DataPoint, slotSize int, fxc *FxConverter, ms *minuteSource, atr []float64) (*WalkForwardWindow, error) {
+	wfw := &WalkForwardWindow{
+		TrainFrom: wr.trainFrom,
+		TestFrom : wr.testFrom,
+		TestTo   : wr.testTo,
+	}
+
+	bc := *btc.BiasConfig
+	if !deriveBestSlots(&bc, btc.excludedSet, dataPoints[wr.trainStart:wr.testStart], slotSize) {
+		wfw.Skipped = true
+		return wfw, nil
+	}
+
+	wfw.StartDay  = bc.StartDay
+	wfw.StartSlot = bc.StartSlot
+	wfw.EndDay    = bc.EndDay
+	wfw.EndSlot   = bc.EndSlot
+
+	//--- The bar before the test window is needed as previous bar
+
+	if wr.testEnd - wr.testStart > 0 && wr.testStart > 0 {
+		testBtc, err := NewBacktestedConfig(&bc, btc.brokerProduct, btc.spec, slotSize)
+		if err != nil {
+			return nil, err
+		}
+
+		testBtc.fxConverter = fxc
+		testBtc.minutes     = ms
+		testBtc.calendar    = btc.calendar
+
+		if btc.atr != nil {
+			testBtc.atr = atr[wr.testStart -1:wr.testEnd]
+		}
+
+		runBacktestedConfigs([]*BacktestedConfig{ testBtc }, dataPoints[wr.testStart -1:wr.testEnd], slotSize)
+
+		for _, bt := range testBtc.Trades {
+			wfw.NetProfit += bt.NetProfit
+		}
+
+		wfw.NumTrades = len(testBtc.Trades)
+		wfw.NetProfit = math.Trunc(wfw.NetProfit)
+		wfc.Trades    = append(wfc.Trades, testBtc.Trades...)
+	}
+
+	return wfw, nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func buildWalkForwardRanges(dataPoints []*ds.DataPoint, spec *WalkForwardSpec, slotSize int, loc *time.Location) []*walkForwardRange {
+	var ranges []*walkForwardRange
+
+	if len(dataPoints) < 2 {
+		return ranges
+	}
+
+	first := getSlotTime(dataPoints[0], slotSize).In(loc)
+	last  := getSlotTime(dataPoints[len(dataPoints) -1], slotSize)
+
+	trainFrom := time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, loc)
+
+	for {
+		testFrom := trainFrom.AddDate(0, spec.TrainMonths, 0)
+		testTo   := testFrom .AddDate(0, spec.TestMonths,  0)
+
+		if testFrom.After(last) {
+			break
+		}
+
+		ranges = append(ranges, &walkForwardRange{
+			trainFrom : trainFrom,
+			testFrom  : testFrom,
+			testTo    : testTo,
+			trainStart: searchSlotTime(dataPoints, trainFrom, slotSize),
+			testStart : searchSlotTime(dataPoints, testFrom,  slotSize),
+			testEnd   : searchSlotTime(dataPoints, testTo,    slotSize),
+		})
+
+		trainFrom = trainFrom.AddDate(0, spec.TestMonths, 0)
+	}
+
+	return ranges
+}
+
+//=============================================================================
+//--- Returns the index of the first bar whose slot starts at or after t
+
+func searchSlotTime(dataPoints []*ds.DataPoint, t time.Time, slotSize int) int {
+	return sort.Search(len(dataPoints), func(i int) bool {
+		return !getSlotTime(dataPoints[i], slotSize).Before(t)
+	})
+}
+
+//=============================================================================
+//--- Keeps the config's days, months and operation, and picks the slot range
+//--- with the best average delta in the training bars. The slots from StartDay
+//--- to EndDay are treated as a single sequence (maximum subarray)
+
+func deriveBestSlots(bc *BiasConfig, es *ExcludedSet, dataPoints []*ds.DataPoint, slotSize int) bool {
+	slotsPerDay := getSlotsPerDay(slotSize)
+	numDays     := int((bc.EndDay - bc.StartDay + 7) % 7) + 1
+
+	sums   := make([]float64, numDays * slotsPerDay)
+	counts := make([]int,     numDays * slotsPerDay)
+
+	for i := 1; i < len(dataPoints); i++ {
+		ti := calcTimeInfo(dataPoints[i], slotSize)
+
+		if !bc.Months[ti.month -1] || es.ShouldBeExcluded(ti.month, ti.year) {
+			continue
+		}
+
+		day := int((ti.dayOfWeek - bc.StartDay + 7) % 7)
+		if day >= numDays {
+			continue
+		}
+
+		delta := dataPoints[i].Close - dataPoints[i-1].Close
+		if bc.Operation == 1 {
+			delta = -delta
+		}
+
+		idx := day * slotsPerDay + int(ti.slot)
+		sums  [idx] += delta
+		counts[idx]++
+	}
+
+	bestSum, bestStart, bestEnd := 0.0, -1, -1
+	currSum, currStart          := 0.0, 0
+
+	for i := range sums {
+		avg := 0.0
+		if counts[i] > 0 {
+			avg = sums[i] / float64(counts[i])
+		}
+
+		if currSum <= 0 {
+			currSum   = avg
+			currStart = i
+		} else {
+			currSum += avg
+		}
+
+		if currSum > bestSum {
+			bestSum   = currSum
+			bestStart = currStart
+			bestEnd   = i
+		}
+	}
+
+	if bestStart == -1 {
+		return false
+	}
+
+	firstDay := bc.StartDay
+
+	bc.StartDay  = (firstDay + int16(bestStart / slotsPerDay)) % 7
+	bc.StartSlot = int16(bestStart % slotsPerDay)
+	bc.EndDay    = (firstDay + int16(bestEnd   / slotsPerDay)) % 7
+	bc.EndSlot   = int16(bestEnd % slotsPerDay)
+
+	return true
+}
+
+//=============================================================================
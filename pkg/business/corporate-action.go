@@ -0,0 +1,353 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/data-collector/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func GetCorporateActions(tx *gorm.DB, c *auth.Context, diId uint) (*[]db.CorporateAction, error) {
+	_, err := getEquityInstrumentAndCheckAccess(tx, c, diId, "GetCorporateActions")
+	if err != nil {
+		return nil, err
+	}
+
+	return db.GetCorporateActionsByInstrumentId(tx, diId)
+}
+
+//=============================================================================
+
+func AddCorporateAction(tx *gorm.DB, c *auth.Context, diId uint, spec *CorporateActionSpec) (*db.CorporateAction, error) {
+	c.Log.Info("AddCorporateAction: Adding a new corporate action", "diId", diId, "type", spec.Type, "exDate", spec.ExDate)
+
+	_, err := getEquityInstrumentAndCheckAccess(tx, c, diId, "AddCorporateAction")
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkCorporateActionSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkDuplicatedAction(tx, c, diId, 0, spec, "AddCorporateAction")
+	if err != nil {
+		return nil, err
+	}
+
+	ca := &db.CorporateAction{
+		DataInstrumentId: diId,
+		ExDate          : spec.ExDate.UTC(),
+		Type            : spec.Type,
+		Value           : spec.Value,
+		Notes           : spec.Notes,
+	}
+
+	err = db.AddCorporateAction(tx, ca)
+	if err != nil {
+		c.Log.Error("AddCorporateAction: Could not add a new corporate action", "error", err.Error())
+		return nil, err
+	}
+
+	c.Log.Info("AddCorporateAction: Corporate action added", "diId", diId, "id", ca.Id)
+	return ca, nil
+}
+
+//=============================================================================
+
+func UpdateCorporateAction(tx *gorm.DB, c *auth.Context, diId uint, id uint, spec *CorporateActionSpec) (*db.CorporateAction, error) {
+	c.Log.Info("UpdateCorporateAction: Updating a corporate action", "diId", diId, "id", id)
+
+	ca, err := getCorporateAction(tx, c, diId, id, "UpdateCorporateAction")
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkCorporateActionSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkDuplicatedAction(tx, c, diId, id, spec, "UpdateCorporateAction")
+	if err != nil {
+		return nil, err
+	}
+
+	ca.ExDate = spec.ExDate.UTC()
+	ca.Type   = spec.Type
+	ca.Value  = spec.Value
+	ca.Notes  = spec.Notes
+
+	err = db.UpdateCorporateAction(tx, ca)
+	if err != nil {
+		c.Log.Error("UpdateCorporateAction: Could not update a corporate action", "error", err.Error())
+		return nil, err
+	}
+
+	c.Log.Info("UpdateCorporateAction: Corporate action updated", "diId", diId, "id", ca.Id)
+	return ca, nil
+}
+
+//=============================================================================
+
+func DeleteCorporateAction(tx *gorm.DB, c *auth.Context, diId uint, id uint) (*db.CorporateAction, error) {
+	c.Log.Info("DeleteCorporateAction: Deleting a corporate action", "diId", diId, "id", id)
+
+	ca, err := getCorporateAction(tx, c, diId, id, "DeleteCorporateAction")
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.DeleteCorporateAction(tx, id)
+	if err != nil {
+		c.Log.Error("DeleteCorporateAction: Could not delete a corporate action", "error", err.Error())
+		return nil, err
+	}
+
+	c.Log.Info("DeleteCorporateAction: Corporate action deleted", "diId", diId, "id", id)
+	return ca, nil
+}
+
+//=============================================================================
+//--- Adds the corporate actions contained into a CSV file. Each line has the
+//--- format: date,type,value[,notes] where date is YYYY-MM-DD. A header line
+//--- is skipped. Actions already present with the same date and type are
+//--- skipped, so that uploading a file twice doesn't adjust prices twice
+
+func UploadCorporateActions(tx *gorm.DB, c *auth.Context, diId uint, reader io.Reader) (*CorporateActionUploadResponse, error) {
+	c.Log.Info("UploadCorporateActions: Uploading corporate actions", "diId", diId)
+
+	_, err := getEquityInstrumentAndCheckAccess(tx, c, diId, "UploadCorporateActions")
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := parseCorporateActions(reader)
+	if err != nil {
+		c.Log.Error("UploadCorporateActions: Bad file format", "error", err.Error())
+		return nil, req.NewBadRequestError(err.Error())
+	}
+
+	existing, err := db.GetCorporateActionsByInstrumentId(tx, diId)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[string]bool{}
+	for _, ca := range *existing {
+		keys[corporateActionKey(ca.ExDate, ca.Type)] = true
+	}
+
+	res := &CorporateActionUploadResponse{}
+
+	for _, spec := range list {
+		err = checkCorporateActionSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		key := corporateActionKey(spec.ExDate, spec.Type)
+		if keys[key] {
+			res.Skipped++
+			continue
+		}
+
+		ca := &db.CorporateAction{
+			DataInstrumentId: diId,
+			ExDate          : spec.ExDate.UTC(),
+			Type            : spec.Type,
+			Value           : spec.Value,
+			Notes           : spec.Notes,
+		}
+
+		err = db.AddCorporateAction(tx, ca)
+		if err != nil {
+			c.Log.Error("UploadCorporateActions: Could not add a corporate action", "error", err.Error())
+			return nil, err
+		}
+
+		keys[key] = true
+		res.Added++
+	}
+
+	c.Log.Info("UploadCorporateActions: Corporate actions added", "diId", diId, "added", res.Added, "skipped", res.Skipped)
+
+	return res, nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func getEquityInstrumentAndCheckAccess(tx *gorm.DB, c *auth.Context, diId uint, function string) (*db.DataInstrument, error) {
+	di, err := getDataInstrumentAndCheckAccess(tx, c, diId, function)
+	if err != nil {
+		return nil, err
+	}
+
+	dp, err := db.GetDataProductById(tx, di.DataProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !dp.SupportsMultipleData || di.VirtualInstrument || di.Synthetic {
+		c.Log.Error(function +": Corporate actions are allowed only on user data", "id", diId)
+		return nil, req.NewBadRequestError("Corporate actions are allowed only on instruments of user products: %v", diId)
+	}
+
+	return di, nil
+}
+
+//=============================================================================
+
+func getCorporateAction(tx *gorm.DB, c *auth.Context, diId uint, id uint, function string) (*db.CorporateAction, error) {
+	_, err := getEquityInstrumentAndCheckAccess(tx, c, diId, function)
+	if err != nil {
+		return nil, err
+	}
+
+	ca, err := db.GetCorporateActionById(tx, id)
+	if err != nil {
+		c.Log.Error(function +": Could not retrieve corporate action", "error", err.Error())
+		return nil, err
+	}
+
+	if ca == nil || ca.DataInstrumentId != diId {
+		c.Log.Error(function +": Corporate action was not found", "diId", diId, "id", id)
+		return nil, req.NewNotFoundError("Corporate action was not found: %v", id)
+	}
+
+	return ca, nil
+}
+
+//=============================================================================
+//--- Only one action of each type is allowed on the same date
+
+func checkDuplicatedAction(tx *gorm.DB, c *auth.Context, diId uint, id uint, spec *CorporateActionSpec, function string) error {
+	ca, err := db.GetCorporateActionByKey(tx, diId, spec.ExDate.UTC(), spec.Type)
+	if err != nil {
+		return err
+	}
+
+	if ca != nil && ca.Id != id {
+		c.Log.Error(function +": Corporate action already exists", "diId", diId, "id", ca.Id)
+		return req.NewBadRequestError("A corporate action of type '%v' already exists on that date: %v", spec.Type, ca.Id)
+	}
+
+	return nil
+}
+
+//=============================================================================
+
+func corporateActionKey(exDate time.Time, caType db.CAType) string {
+	return exDate.UTC().Format(time.DateOnly) +"|"+ string(caType)
+}
+
+//=============================================================================
+
+func checkCorporateActionSpec(spec *CorporateActionSpec) error {
+	switch spec.Type {
+		case db.CATypeSplit:
+			if spec.Value <= 0 {
+				return req.NewBadRequestError("Split ratio must be positive: %v", spec.Value)
+			}
+		case db.CATypeDividend:
+			if spec.Value <= 0 {
+				return req.NewBadRequestError("Dividend amount must be positive: %v", spec.Value)
+			}
+		default:
+			return req.NewBadRequestError("Unknown corporate action type: %v", spec.Type)
+	}
+
+	return nil
+}
+
+//=============================================================================
+
+func parseCorporateActions(reader io.Reader) ([]*CorporateActionSpec, error) {
+	r := csv.NewReader(reader)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	var list []*CorporateActionSpec
+	line := 0
+
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return list, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line++
+		if len(rec) < 3 {
+			return nil, errors.New("line "+ strconv.Itoa(line) +": expected date,type,value[,notes]")
+		}
+
+		date, err := time.Parse(time.DateOnly, strings.TrimSpace(rec[0]))
+		if err != nil {
+			//--- Header line
+			if line == 1 {
+				continue
+			}
+			return nil, errors.New("line "+ strconv.Itoa(line) +": bad date: "+ rec[0])
+		}
+
+		value, err := strconv.ParseFloat(strings.TrimSpace(rec[2]), 64)
+		if err != nil {
+			return nil, errors.New("line "+ strconv.Itoa(line) +": bad value: "+ rec[2])
+		}
+
+		spec := &CorporateActionSpec{
+			ExDate: date,
+			Type  : db.CAType(strings.ToLower(strings.TrimSpace(rec[1]))),
+			Value : value,
+		}
+
+		if len(rec) > 3 {
+			spec.Notes = strings.TrimSpace(rec[3])
+		}
+
+		list = append(list, spec)
+	}
+}
+
+//=============================================================================
@@ -0,0 +1,383 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+const (
+	MaxCorrelationInstruments = 20
+	DefaultCorrelationWindow  = 50
+)
+
+//=============================================================================
+
+type CorrelationSpec struct {
+	Ids       string
+	Timeframe string
+	From      string
+	To        string
+	Timezone  string
+	Pair      string
+	Window    string
+	ids       []uint
+	pair      []uint
+	window    int
+	configs   []*DataConfig
+}
+
+//=============================================================================
+
+type CorrelationResponse struct {
+	Ids       []uint              `json:"ids"`
+	Symbols   []string            `json:"symbols"`
+	From      string              `json:"from"`
+	To        string              `json:"to"`
+	Timeframe string              `json:"timeframe"`
+	Timezone  string              `json:"timezone"`
+	Bars      int                 `json:"bars"`
+	Matrix    [][]float64         `json:"matrix"`
+	Rolling   *RollingCorrelation `json:"rolling,omitempty"`
+}
+
+//=============================================================================
+
+type RollingCorrelation struct {
+	Ids    []uint              `json:"ids"`
+	Window int                 `json:"window"`
+	Points []*CorrelationPoint `json:"points"`
+}
+
+//=============================================================================
+
+type CorrelationPoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+//=============================================================================
+
+func GetCorrelationConfigs(tx *gorm.DB, c *auth.Context, spec *CorrelationSpec) error {
+	err := parseCorrelationSpec(spec)
+	if err != nil {
+		return req.NewBadRequestError(err.Error())
+	}
+
+	c.Log.Info("GetCorrelationConfigs: Getting data instruments", "ids", spec.ids)
+
+	for _, id := range spec.ids {
+		_, err = getDataInstrumentAndCheckAccess(tx, c, id, "GetCorrelationConfigs")
+		if err != nil {
+			return err
+		}
+
+		var config *DataConfig
+		config, err = CreateDataConfig(tx, id)
+		if err != nil {
+			return err
+		}
+
+		spec.configs = append(spec.configs, config)
+	}
+
+	return nil
+}
+
+//=============================================================================
+//--- Bars are aligned on the timestamps common to all instruments. Correlations
+//--- are calculated on the log returns between consecutive common timestamps.
+//--- Back-adjusted and synthetic series can go negative, so price differences
+//--- are used for them
+
+func GetCorrelations(c *auth.Context, spec *CorrelationSpec) (*CorrelationResponse, error) {
+	var series []map[int64]float64
+	var diffs  []bool
+	var params *DataInstrumentDataParams
+
+	cr := &CorrelationResponse{
+		Ids      : spec.ids,
+		Timeframe: spec.Timeframe,
+	}
+
+	for i, config := range spec.configs {
+		config.DataConfig.Timeframe = spec.Timeframe
+
+		dataSpec := &DataInstrumentDataSpec{
+			Id      : spec.ids[i],
+			From    : spec.From,
+			To      : spec.To,
+			Timezone: spec.Timezone,
+			Config  : config,
+		}
+
+		var err error
+		params, err = parseInstrumentDataParams(dataSpec)
+		if err != nil {
+			return nil, req.NewBadRequestError(err.Error())
+		}
+
+		dataPoints, err := getDataPoints(params, config)
+		if err != nil {
+			return nil, err
+		}
+
+		closes := map[int64]float64{}
+		for _, dp := range dataPoints {
+			closes[dp.Time.Unix()] = dp.Close
+		}
+
+		series     = append(series, closes)
+		diffs      = append(diffs, config.VirtualInstrument || config.Synthetic != nil)
+		cr.Symbols = append(cr.Symbols, config.DataConfig.Symbol)
+	}
+
+	cr.From     = params.From.Format(time.DateTime)
+	cr.To       = params.To.Format(time.DateTime)
+	cr.Timezone = params.Location.String()
+
+	times        := getCommonTimes(series)
+	returns, err := calcAlignedReturns(series, diffs, times, cr.Symbols)
+	if err != nil {
+		c.Log.Error("GetCorrelations: Cannot calculate returns", "error", err.Error())
+		return nil, req.NewBadRequestError(err.Error())
+	}
+
+	cr.Bars = len(times)
+
+	c.Log.Info("GetCorrelations: Bars aligned", "ids", spec.ids, "commonBars", len(times))
+
+	cr.Matrix = make([][]float64, len(returns))
+	for i := range returns {
+		cr.Matrix[i] = make([]float64, len(returns))
+		for j := range returns {
+			if i == j {
+				cr.Matrix[i][j] = 1
+			} else if j < i {
+				cr.Matrix[i][j] = cr.Matrix[j][i]
+			} else {
+				cr.Matrix[i][j] = calcCorrelation(returns[i], returns[j])
+			}
+		}
+	}
+
+	if spec.pair != nil {
+		cr.Rolling = calcRollingCorrelation(spec, returns, times, params.Location)
+	}
+
+	return cr, nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func parseCorrelationSpec(spec *CorrelationSpec) error {
+	ids, err := parseIdList(spec.Ids)
+	if err != nil {
+		return errors.New("Bad ids: "+ spec.Ids +" ("+ err.Error() +")")
+	}
+
+	if len(ids) < 2 || len(ids) > MaxCorrelationInstruments {
+		return errors.New("Bad ids: the number of instruments must be in the range 2.."+ strconv.Itoa(MaxCorrelationInstruments))
+	}
+
+	spec.ids    = ids
+	spec.window = DefaultCorrelationWindow
+
+	if spec.Pair != "" {
+		spec.pair, err = parseIdList(spec.Pair)
+		if err != nil || len(spec.pair) != 2 || indexOfId(ids, spec.pair[0]) == -1 || indexOfId(ids, spec.pair[1]) == -1 {
+			return errors.New("Bad pair: "+ spec.Pair +" (must be 2 ids from the list)")
+		}
+	}
+
+	if spec.Window != "" {
+		spec.window, err = strconv.Atoi(spec.Window)
+		if err != nil || spec.window < 10 || spec.window > 1000 {
+			return errors.New("Bad window: "+ spec.Window +" (allowed range is 10..1000)")
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
+
+func parseIdList(value string) ([]uint, error) {
+	var ids []uint
+
+	for _, item := range strings.Split(value, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(item), 10, 32)
+		if err != nil {
+			return nil, err
+		}
+
+		if indexOfId(ids, uint(id)) == -1 {
+			ids = append(ids, uint(id))
+		}
+	}
+
+	return ids, nil
+}
+
+//=============================================================================
+
+func indexOfId(ids []uint, id uint) int {
+	for i, curr := range ids {
+		if curr == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+//=============================================================================
+
+func getCommonTimes(series []map[int64]float64) []int64 {
+	var times []int64
+
+	for t := range series[0] {
+		common := true
+		for _, s := range series[1:] {
+			if _, ok := s[t]; !ok {
+				common = false
+				break
+			}
+		}
+
+		if common {
+			times = append(times, t)
+		}
+	}
+
+	sort.Slice(times, func(i, j int) bool {
+		return times[i] < times[j]
+	})
+
+	return times
+}
+
+//=============================================================================
+
+func calcAlignedReturns(series []map[int64]float64, diffs []bool, times []int64, symbols []string) ([][]float64, error) {
+	var returns [][]float64
+
+	for i, s := range series {
+		var list []float64
+
+		for j := 1; j < len(times); j++ {
+			prev := s[times[j-1]]
+			curr := s[times[j]]
+
+			if diffs[i] {
+				list = append(list, curr - prev)
+			} else if prev > 0 && curr > 0 {
+				list = append(list, math.Log(curr / prev))
+			} else {
+				return nil, errors.New("Prices of '"+ symbols[i] +"' must be positive to calculate returns: "+ time.Unix(times[j], 0).UTC().Format(time.DateTime))
+			}
+		}
+
+		returns = append(returns, list)
+	}
+
+	return returns, nil
+}
+
+//=============================================================================
+//--- Pearson correlation. NaN cannot be marshalled so 0 is returned when one
+//--- series is constant
+
+func calcCorrelation(x, y []float64) float64 {
+	n := float64(len(x))
+	if n < 2 {
+		return 0
+	}
+
+	var sumX, sumY float64
+	for i := range x {
+		sumX += x[i]
+		sumY += y[i]
+	}
+
+	meanX := sumX / n
+	meanY := sumY / n
+
+	var cov, varX, varY float64
+	for i := range x {
+		dx := x[i] - meanX
+		dy := y[i] - meanY
+		cov  += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+
+	if varX == 0 || varY == 0 {
+		return 0
+	}
+
+	return cov / math.Sqrt(varX * varY)
+}
+
+//=============================================================================
+
+func calcRollingCorrelation(spec *CorrelationSpec, returns [][]float64, times []int64, loc *time.Location) *RollingCorrelation {
+	x := returns[indexOfId(spec.ids, spec.pair[0])]
+	y := returns[indexOfId(spec.ids, spec.pair[1])]
+
+	rc := &RollingCorrelation{
+		Ids   : spec.pair,
+		Window: spec.window,
+		Points: []*CorrelationPoint{},
+	}
+
+	//--- returns[i] is the return between times[i] and times[i+1]
+
+	for i := spec.window; i <= len(x); i++ {
+		rc.Points = append(rc.Points, &CorrelationPoint{
+			Time : time.Unix(times[i], 0).In(loc),
+			Value: calcCorrelation(x[i - spec.window:i], y[i - spec.window:i]),
+		})
+	}
+
+	return rc
+}
+
+//=============================================================================
@@ -0,0 +1,140 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"errors"
+
+	"github.com/bit-fever/data-collector/pkg/db"
+	"github.com/bit-fever/data-collector/pkg/ds"
+)
+
+//=============================================================================
+//--- Slippage is per side and is the sum of a fixed number of ticks and of a
+//--- fraction of the bar's range. Take profit exits are limit orders and do
+//--- not slip, while stop exits slip StopFactor times the market orders.
+//--- CommissionPerSide overrides the broker product's cost per operation and
+//--- TickSize is used when the broker product has none
+
+type CostModelSpec struct {
+	SlippageTicks     float64  `json:"slippageTicks"`
+	SlippageRange     float64  `json:"slippageRange"`
+	StopFactor        float64  `json:"stopFactor"`
+	CommissionPerSide *float64 `json:"commissionPerSide"`
+	TickSize          float64  `json:"tickSize"`
+}
+
+//=============================================================================
+
+type costModel struct {
+	pointValue    float64
+	commission    float64
+	tickSlippage  float64
+	rangeFraction float64
+	stopFactor    float64
+}
+
+//=============================================================================
+
+func newCostModel(spec *CostModelSpec, bp *db.BrokerProduct) *costModel {
+	cm := &costModel{
+		pointValue: float64(bp.PointValue),
+		commission: float64(bp.CostPerOperation),
+		stopFactor: 1,
+	}
+
+	if spec != nil {
+		if spec.CommissionPerSide != nil {
+			cm.commission = *spec.CommissionPerSide
+		}
+
+		cm.tickSlippage  = spec.SlippageTicks * getTickSize(spec, bp)
+		cm.rangeFraction = spec.SlippageRange
+
+		if spec.StopFactor > 0 {
+			cm.stopFactor = spec.StopFactor
+		}
+	}
+
+	return cm
+}
+
+//=============================================================================
+//--- Returns the slippage in points of a market order filled in the bar
+
+func (cm *costModel) marketSlippage(dp *ds.DataPoint) float64 {
+	return cm.tickSlippage + cm.rangeFraction * (dp.High - dp.Low)
+}
+
+//=============================================================================
+
+func (cm *costModel) exitSlippage(dp *ds.DataPoint, exitCondition int8) float64 {
+	switch exitCondition {
+		case ExitConditionProfit:
+			return 0
+		case ExitConditionStop, ExitConditionTrailingStop, ExitConditionBreakeven:
+			return cm.marketSlippage(dp) * cm.stopFactor
+	}
+
+	return cm.marketSlippage(dp)
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func checkCostModelSpec(spec *CostModelSpec, bp *db.BrokerProduct) error {
+	if spec.SlippageTicks < 0 || spec.SlippageRange < 0 || spec.StopFactor < 0 || spec.TickSize < 0 {
+		return errors.New("costs cannot be negative")
+	}
+
+	if spec.CommissionPerSide != nil && *spec.CommissionPerSide < 0 {
+		return errors.New("commissionPerSide cannot be negative")
+	}
+
+	if spec.SlippageRange > 1 {
+		return errors.New("slippageRange must be a fraction of the bar range (0..1)")
+	}
+
+	if spec.SlippageTicks > 0 && getTickSize(spec, bp) == 0 {
+		return errors.New("slippageTicks requires a tick size, but the broker product has none")
+	}
+
+	return nil
+}
+
+//=============================================================================
+
+func getTickSize(spec *CostModelSpec, bp *db.BrokerProduct) float64 {
+	if bp.TickSize > 0 {
+		return float64(bp.TickSize)
+	}
+
+	return spec.TickSize
+}
+
+//=============================================================================
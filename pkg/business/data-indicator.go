@@ -0,0 +1,133 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bit-fever/data-collector/pkg/core/indicator"
+	"github.com/bit-fever/data-collector/pkg/ds"
+)
+
+//=============================================================================
+
+//--- Bars are not contiguous (nights, weekends, holidays) so the warm-up period
+//--- is enlarged to be sure to get enough bars
+const (
+	WarmupFactor = 5
+	WarmupExtra  = 4 * 24 * time.Hour
+)
+
+//=============================================================================
+
+func parseIndicators(value string) ([]*indicator.Spec, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	return indicator.Parse(value)
+}
+
+//=============================================================================
+//--- Returns how far before 'from' data must be loaded to compute the indicators
+
+func getIndicatorsWarmup(specs []*indicator.Spec, timeframe string) time.Duration {
+	if len(specs) == 0 {
+		return 0
+	}
+
+	bars := 0
+	for _, s := range specs {
+		bars = max(bars, s.WarmupBars())
+	}
+
+	mins, err := strconv.Atoi(strings.TrimSuffix(timeframe, "m"))
+	if err != nil {
+		mins = 1440
+	}
+
+	return time.Duration(bars * mins * WarmupFactor) * time.Minute + WarmupExtra
+}
+
+//=============================================================================
+//--- Computes the indicators over all data points (warm-up included) and then
+//--- removes the bars before 'from'
+
+func calcIndicators(specs []*indicator.Spec, dataPoints []*ds.DataPoint, from time.Time, loc *time.Location) ([]*ds.DataPoint, []*indicator.Series) {
+	var series []*indicator.Series
+
+	for _, s := range specs {
+		series = append(series, s.Compute(dataPoints, loc)...)
+	}
+
+	start := 0
+	for start < len(dataPoints) && dataPoints[start].Time.Before(from) {
+		start++
+	}
+
+	for _, s := range series {
+		s.Values = s.Values[start:]
+	}
+
+	return dataPoints[start:], series
+}
+
+//=============================================================================
+//--- Picks the values of the bars selected by the reduction. NaN values are
+//--- converted to nil as they cannot be marshalled into JSON
+
+func buildIndicatorSeries(series []*indicator.Series, indexes []int) []*IndicatorSeries {
+	var list []*IndicatorSeries
+
+	for _, s := range series {
+		values := s.Values
+		if indexes != nil {
+			values = make([]float64, len(indexes))
+			for i, index := range indexes {
+				values[i] = s.Values[index]
+			}
+		}
+
+		is := &IndicatorSeries{
+			Name  : s.Name,
+			Values: make([]*float64, len(values)),
+		}
+
+		for i := range values {
+			if !math.IsNaN(values[i]) {
+				is.Values[i] = &values[i]
+			}
+		}
+
+		list = append(list, is)
+	}
+
+	return list
+}
+
+//=============================================================================
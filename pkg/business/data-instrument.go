@@ -27,13 +27,13 @@ package business
 import (
 	"errors"
 	"log/slog"
-	"math"
-	"strconv"
 	"time"
 
 	"github.com/bit-fever/core/auth"
 	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/data-collector/pkg/core/indicator"
 	"github.com/bit-fever/data-collector/pkg/core/jobmanager"
+	"github.com/bit-fever/data-collector/pkg/core/messaging/rollover"
 	"github.com/bit-fever/data-collector/pkg/core/process/invloader"
 	"github.com/bit-fever/data-collector/pkg/db"
 	"github.com/bit-fever/data-collector/pkg/ds"
@@ -63,12 +63,18 @@ func CreateDataConfig(tx *gorm.DB, id uint) (*DataConfig, error) {
 	if err == nil {
 		p, err = db.GetDataProductById(tx, i.DataProductId)
 		if err == nil {
+			if i.Synthetic {
+				return createSyntheticConfig(tx, i, p)
+			}
+
 			var instruments *[]db.DataInstrument
 			if i.VirtualInstrument {
-				instruments,err = db.GetRollingDataInstrumentsByProductIdFast(tx, p.Id, p.Months)
+				instruments,err = getVirtualInstruments(tx, i, p)
 			}
 
-			return createConfig(i, p, instruments), nil
+			if err == nil {
+				return createConfig(i, p, instruments), nil
+			}
 		}
 	}
 
@@ -106,6 +112,11 @@ func GetDataInstrumentDataById(c *auth.Context, spec *DataInstrumentDataSpec)(*D
 
 	var dataPoints []*ds.DataPoint
 
+	//--- Indicators need some bars before 'from' to be correct at the start
+
+	from := params.From
+	params.From = from.Add(-params.Warmup)
+
 	start := time.Now()
 	dataPoints, err = getDataPoints(params, spec.Config)
 	durQ := time.Now().Sub(start).Seconds()
@@ -116,9 +127,40 @@ func GetDataInstrumentDataById(c *auth.Context, spec *DataInstrumentDataSpec)(*D
 
 	noDataForVirtual := dataPoints == nil
 
+	adjustment := ""
+	if spec.Config.Adjustment != nil {
+		err = spec.Config.Adjustment.AdjustBars(params, spec.Config, dataPoints)
+		if err != nil {
+			return nil, err
+		}
+
+		adjustment = spec.Config.Adjustment.Mode
+	}
+
+	currency := spec.Config.CurrencyCode
+	if spec.Config.Fx != nil {
+		var fxc *FxConverter
+		fxc, err = spec.Config.Fx.newConverter(params, spec.Config.DataConfig.Timeframe)
+		if err != nil {
+			return nil, err
+		}
+
+		fxc.ConvertBars(dataPoints)
+		currency = spec.Config.Fx.To
+	}
+
+	var series []*indicator.Series
+	if len(params.Indicators) != 0 {
+		prLoc,_ := time.LoadLocation(spec.Config.Timezone)
+		dataPoints, series = calcIndicators(params.Indicators, dataPoints, from, prLoc)
+	}
+
+	params.From = from
+
 	start = time.Now()
 	reduced := false
-	dataPoints,reduced = reduceDataPoints(dataPoints, params.Reduction)
+	var indexes []int
+	dataPoints,indexes,reduced = reduceDataPoints(dataPoints, params.Reduction, params.Algorithm)
 	durR := time.Now().Sub(start).Seconds()
 	lenR := len(dataPoints)
 
@@ -133,9 +175,13 @@ func GetDataInstrumentDataById(c *auth.Context, spec *DataInstrumentDataSpec)(*D
 		Timezone        : params.Location.String(),
 		Reduction       : params.Reduction,
 		Reduced         : reduced,
+		Algorithm       : params.Algorithm,
 		NoDataForVirtual: noDataForVirtual,
+		Currency        : currency,
+		Adjustment      : adjustment,
 		Records         : len(dataPoints),
 		DataPoints      : dataPoints,
+		Indicators      : buildIndicatorSeries(series, indexes),
 	}, nil
 }
 
@@ -178,15 +224,17 @@ func ReloadDataInstrumentData(tx *gorm.DB, c *auth.Context, id uint) (*db.Downlo
 		return nil,nil,req.NewBadRequestError("Data instrument must be READY or EMPTY. Id=", id)
 	}
 
-	//--- Update data instrument
+	//--- Update data instrument (a manual rollover is kept)
 
-	di.RolloverDate  = nil
-	di.RolloverDelta = 0
-	di.RolloverStatus= db.DIRollStatusWaiting
+	if !di.RolloverManual {
+		di.RolloverDate  = nil
+		di.RolloverDelta = 0
+		di.RolloverStatus= db.DIRollStatusWaiting
 
-	err = db.UpdateDataInstrument(tx, di)
-	if err != nil {
-		return nil,nil,req.NewServerErrorByError(err)
+		err = db.UpdateDataInstrument(tx, di)
+		if err != nil {
+			return nil,nil,req.NewServerErrorByError(err)
+		}
 	}
 
 	//--- Update data block
@@ -236,9 +284,36 @@ func createConfig(i *db.DataInstrument, p *db.DataProduct, instruments *[]db.Dat
 		Timezone         : p.Timezone,
 		VirtualInstrument: i.VirtualInstrument,
 		Instruments      : instruments,
+		CurrencyCode     : p.CurrencyCode,
 	}
 }
 
+//=============================================================================
+//--- Returns the rolling instruments of a virtual instrument, with the rollover
+//--- fields that belong to it
+
+func getVirtualInstruments(tx *gorm.DB, vi *db.DataInstrument, p *db.DataProduct) (*[]db.DataInstrument, error) {
+	months, _ := rollover.GetVirtualSettings(vi, p)
+
+	list, err := db.GetRollingDataInstrumentsByProductIdFast(tx, p.Id, months)
+	if err != nil || vi.VirtualMonths == "" {
+		return list, err
+	}
+
+	vrs, err := db.GetVirtualRolloversByVirtualId(tx, vi.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	var instruments []*db.DataInstrument
+	for i := range *list {
+		instruments = append(instruments, &(*list)[i])
+	}
+	rollover.ApplyVirtualRollovers(instruments, vrs)
+
+	return list, nil
+}
+
 //=============================================================================
 
 func parseInstrumentDataParams(spec *DataInstrumentDataSpec) (*DataInstrumentDataParams, error) {
@@ -263,6 +338,10 @@ func parseInstrumentDataParams(spec *DataInstrumentDataSpec) (*DataInstrumentDat
 		return nil, errors.New("Bad product timezone: "+ spec.Config.Timezone)
 	}
 
+	//--- The aggregator can change the timeframe to query, so we need to take it now
+
+	timeframe := spec.Config.DataConfig.Timeframe
+
 	da, err3 := buildDataAggregator(&spec.Config.DataConfig, prLoc)
 	if err3 != nil {
 		return nil, errors.New("Bad timeframe: "+ spec.Config.DataConfig.Timeframe +" ("+ err3.Error() +")")
@@ -274,11 +353,26 @@ func parseInstrumentDataParams(spec *DataInstrumentDataSpec) (*DataInstrumentDat
 		return nil, errors.New("Bad reduction: "+ spec.Reduction +" ("+ err.Error() +")")
 	}
 
+	alg, err := parseAlgorithm(spec.Algorithm)
+
+	if err != nil {
+		return nil, errors.New("Bad algorithm: "+ spec.Algorithm +" ("+ err.Error() +")")
+	}
+
+	ind, err := parseIndicators(spec.Indicators)
+
+	if err != nil {
+		return nil, errors.New("Bad indicators: "+ spec.Indicators +" ("+ err.Error() +")")
+	}
+
 	return &DataInstrumentDataParams{
 		Location  : loc,
 		From      : from.UTC(),
 		To        : to.UTC(),
 		Reduction : red,
+		Algorithm : alg,
+		Indicators: ind,
+		Warmup    : getIndicatorsWarmup(ind, timeframe),
 		Aggregator: da,
 	}, nil
 }
@@ -324,65 +418,6 @@ func buildDataAggregator(config *ds.DataConfig, productLoc *time.Location) (*ds.
 	return nil, errors.New("allowed values are 1m, 5m, 10m, 15m, 30m, 60m")
 }
 
-//=============================================================================
-
-func parseReduction(value string) (int, error) {
-	if value == "" {
-		return 0, nil
-	}
-
-	red, err := strconv.Atoi(value)
-
-	if err != nil {
-		return 0, err
-	}
-
-	if red == 0 {
-		return red, nil
-	}
-
-	if red < 100 || red > 100000 {
-		return 0, errors.New("allowed range is 100..100000")
-	}
-
-	return red,nil
-}
-
-//=============================================================================
-
-func reduceDataPoints(dataPoints []*ds.DataPoint, reduction int) ([]*ds.DataPoint, bool) {
-	if reduction == 0 || len(dataPoints) <= reduction {
-		return dataPoints, false
-	}
-
-	shrinkSize := len(dataPoints) / reduction +1
-
-	var list []*ds.DataPoint
-	var currDp *ds.DataPoint = nil
-	var count = 0
-
-	for _,dp := range dataPoints {
-		if currDp == nil {
-			currDp = dp
-		} else {
-			currDp.High        = math.Max(currDp.High, dp.High)
-			currDp.Low         = math.Min(currDp.Low,  dp.Low)
-			currDp.Close       = dp.Close
-			currDp.UpVolume   += dp.UpVolume
-			currDp.DownVolume += dp.DownVolume
-		}
-
-		count++
-		if count == shrinkSize {
-			list   = append(list, currDp)
-			currDp = nil
-			count  = 0
-		}
-	}
-
-	return list, true
-}
-
 //=============================================================================
 //===
 //=== Query splitting
@@ -390,6 +425,10 @@ func reduceDataPoints(dataPoints []*ds.DataPoint, reduction int) ([]*ds.DataPoin
 //=============================================================================
 
 func getDataPoints(params *DataInstrumentDataParams, config *DataConfig) ([]*ds.DataPoint,error) {
+	if config.Synthetic != nil {
+		return getSyntheticDataPoints(params, config)
+	}
+
 	if !config.VirtualInstrument {
 		err := ds.GetDataPoints(params.From, params.To, &config.DataConfig, params.Location, params.Aggregator)
 		return params.Aggregator.DataPoints(),err
@@ -397,13 +436,16 @@ func getDataPoints(params *DataInstrumentDataParams, config *DataConfig) ([]*ds.
 
 	//--- Querying the virtual instrument. We need to split into several queries
 
-	chunks := calcInstrumentListToQuery(params.From, params.To, config.Instruments)
+	adjustTo := params.To
+	if !params.AdjustTo.IsZero() {
+		adjustTo = params.AdjustTo
+	}
+
+	chunks := calcChunksToQuery(params.From, params.To, adjustTo, config.Instruments)
 	if chunks == nil {
 		return nil,nil
 	}
 
-	cumulateDeltas(chunks)
-
 	from    := params.From
 	dconfig := &config.DataConfig
 	aggreg  := ds.NewDataAggregator(nil, nil)
@@ -426,6 +468,18 @@ func getDataPoints(params *DataInstrumentDataParams, config *DataConfig) ([]*ds.
 	return aggreg.DataPoints(),nil
 }
 
+//=============================================================================
+//--- Params to query the whole history of an instrument
+
+func newDefaultDataParams(loc *time.Location, da *ds.DataAggregator) *DataInstrumentDataParams {
+	return &DataInstrumentDataParams{
+		Location  : loc,
+		From      : DefaultFrom,
+		To        : DefaultTo,
+		Aggregator: da,
+	}
+}
+
 //=============================================================================
 
 func calcInstrumentListToQuery(from,to time.Time, list *[]db.DataInstrument) *[]*QueryChunk {
@@ -436,7 +490,7 @@ func calcInstrumentListToQuery(from,to time.Time, list *[]db.DataInstrument) *[]
 			continue
 		}
 
-		if di.RolloverStatus == db.DIRollStatusReady  {
+		if di.RolloverStatus == db.DIRollStatusReady || di.RolloverStatus == db.DIRollStatusLowConfidence {
 			if from.Compare(*di.RolloverDate) <= 0 {
 				res = append(res, buildQueryChunk(&di))
 			}
@@ -460,6 +514,33 @@ func calcInstrumentListToQuery(from,to time.Time, list *[]db.DataInstrument) *[]
 	return nil
 }
 
+//=============================================================================
+//--- Deltas are cumulated up to the instrument at 'adjustTo', so that a shorter
+//--- period gets the same adjustment of a longer one. Then chunks after 'to' are
+//--- dropped. With adjustTo == to, this is the plain list of instruments
+
+func calcChunksToQuery(from,to,adjustTo time.Time, list *[]db.DataInstrument) *[]*QueryChunk {
+	chunks := calcInstrumentListToQuery(from, adjustTo, list)
+	if chunks == nil {
+		//--- No instrument is waiting, so the history ends before adjustTo
+		chunks = calcInstrumentListToQuery(from, to, list)
+		if chunks == nil {
+			return nil
+		}
+	}
+
+	cumulateDeltas(chunks)
+
+	for i,c := range *chunks {
+		if to.Compare(c.RolloverDate) <= 0 {
+			res := (*chunks)[:i+1]
+			return &res
+		}
+	}
+
+	return chunks
+}
+
 //=============================================================================
 
 func buildQueryChunk(di *db.DataInstrument) *QueryChunk {
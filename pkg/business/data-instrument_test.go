@@ -0,0 +1,95 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bit-fever/data-collector/pkg/db"
+)
+
+//=============================================================================
+
+func rollDate(y int, m time.Month, d int) *time.Time {
+	t := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+//-----------------------------------------------------------------------------
+
+var rollInstruments = []db.DataInstrument{
+	{Symbol:"ESH10", RolloverDate:rollDate(2010,3,10), ExpirationDate:rollDate(2010,3,19), RolloverDelta:2, RolloverStatus:db.DIRollStatusReady},
+	{Symbol:"ESM10", RolloverDate:rollDate(2010,6,10), ExpirationDate:rollDate(2010,6,18), RolloverDelta:3, RolloverStatus:db.DIRollStatusReady},
+	{Symbol:"ESU10",                                   ExpirationDate:rollDate(2010,9,17),                  RolloverStatus:db.DIRollStatusWaiting},
+}
+
+//-----------------------------------------------------------------------------
+
+func formatChunks(chunks *[]*QueryChunk) []string {
+	var res []string
+
+	if chunks != nil {
+		for _, c := range *chunks {
+			res = append(res, fmt.Sprintf("%v:%v", c.Symbol, c.Delta))
+		}
+	}
+
+	return res
+}
+
+//=============================================================================
+//--- A short period adjusted up to the full history must get the same deltas
+
+func TestCalcChunksToQuery(t *testing.T) {
+	from := time.Date(2010,1,1,0,0,0,0, time.UTC)
+	feb  := time.Date(2010,2,1,0,0,0,0, time.UTC)
+	apr  := time.Date(2010,4,1,0,0,0,0, time.UTC)
+
+	tests := []struct {
+		name     string
+		to       time.Time
+		adjustTo time.Time
+		expected []string
+	}{
+		{"full history",          DefaultTo, DefaultTo, []string{"ESH10:5", "ESM10:3", "ESU10:0"}},
+		{"one chunk, full adj",   feb,       DefaultTo, []string{"ESH10:5"}},
+		{"two chunks, full adj",  apr,       DefaultTo, []string{"ESH10:5", "ESM10:3"}},
+		{"one chunk, own adj",    feb,       feb,       []string{"ESH10:2"}},
+		{"two chunks, own adj",   apr,       apr,       []string{"ESH10:2", "ESM10:3"}},
+	}
+
+	for _, tt := range tests {
+		chunks := formatChunks(calcChunksToQuery(from, tt.to, tt.adjustTo, &rollInstruments))
+
+		if !slices.Equal(chunks, tt.expected) {
+			t.Errorf("%v: expected %v but got %v", tt.name, tt.expected, chunks)
+		}
+	}
+}
+
+//=============================================================================
@@ -0,0 +1,257 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/bit-fever/data-collector/pkg/ds"
+)
+
+//=============================================================================
+
+const (
+	ReductionMerge  = "merge"
+	ReductionLttb   = "lttb"
+	ReductionMinMax = "minmax"
+)
+
+//=============================================================================
+
+func parseReduction(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	red, err := strconv.Atoi(value)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if red == 0 {
+		return red, nil
+	}
+
+	if red < 100 || red > 100000 {
+		return 0, errors.New("allowed range is 100..100000")
+	}
+
+	return red,nil
+}
+
+//=============================================================================
+
+func parseAlgorithm(value string) (string, error) {
+	if value == "" {
+		return ReductionMerge, nil
+	}
+
+	if value == ReductionMerge || value == ReductionLttb || value == ReductionMinMax {
+		return value, nil
+	}
+
+	return "", errors.New("allowed values are merge, lttb, minmax")
+}
+
+//=============================================================================
+//--- Source data points are never modified: reduced bars are always new objects
+//--- or untouched source bars. For each reduced bar, the index of the source bar
+//--- that gives its close is returned too, so that other series can be reduced
+//--- without lagging the bars
+
+func reduceDataPoints(dataPoints []*ds.DataPoint, reduction int, algorithm string) ([]*ds.DataPoint, []int, bool) {
+	if reduction == 0 || len(dataPoints) <= reduction {
+		return dataPoints, nil, false
+	}
+
+	switch algorithm {
+		case ReductionLttb:
+			list, indexes := reduceLttb(dataPoints, reduction)
+			return list, indexes, true
+		case ReductionMinMax:
+			list, indexes := reduceMinMax(dataPoints, reduction)
+			return list, indexes, true
+	}
+
+	list, indexes := reduceMerge(dataPoints, reduction)
+	return list, indexes, true
+}
+
+//=============================================================================
+//===
+//=== Algorithms
+//===
+//=============================================================================
+//--- Merges every len/reduction+1 bars into one, keeping the time of the first bar
+
+func reduceMerge(dataPoints []*ds.DataPoint, reduction int) ([]*ds.DataPoint, []int) {
+	shrinkSize := len(dataPoints) / reduction +1
+
+	var list    []*ds.DataPoint
+	var indexes []int
+
+	for i := 0; i < len(dataPoints); i += shrinkSize {
+		end   := min(i + shrinkSize, len(dataPoints))
+		newDp := mergeBucket(dataPoints[i:end])
+		newDp.Time = dataPoints[i].Time
+		list    = append(list, newDp)
+		indexes = append(indexes, end -1)
+	}
+
+	return list, indexes
+}
+
+//=============================================================================
+//--- Largest-Triangle-Three-Buckets on the close price. The first and last bars
+//--- are always kept and, for each bucket, the bar that forms the largest triangle
+//--- with the previously selected bar and the average of the next bucket is taken.
+//--- Time is used as x axis so that gaps (nights, weekends) are properly weighted
+
+func reduceLttb(dataPoints []*ds.DataPoint, reduction int) ([]*ds.DataPoint, []int) {
+	size := len(dataPoints)
+	if reduction < 3 {
+		return []*ds.DataPoint{ dataPoints[0], dataPoints[size -1] }, []int{ 0, size -1 }
+	}
+
+	indexes := make([]int, 0, reduction)
+	indexes  = append(indexes, 0)
+
+	bucketSize := float64(size -2) / float64(reduction -2)
+	selected   := 0
+
+	for i := 0; i < reduction -2; i++ {
+		//--- Average point of the next bucket
+
+		nextStart := int(float64(i+1) * bucketSize) +1
+		nextEnd   := min(int(float64(i+2) * bucketSize) +1, size)
+
+		avgX := 0.0
+		avgY := 0.0
+		for j := nextStart; j < nextEnd; j++ {
+			avgX += lttbX(dataPoints[j])
+			avgY += dataPoints[j].Close
+		}
+
+		count := float64(nextEnd - nextStart)
+		avgX /= count
+		avgY /= count
+
+		//--- Point of the current bucket with the largest triangle
+
+		currStart := int(float64(i)   * bucketSize) +1
+		currEnd   := int(float64(i+1) * bucketSize) +1
+
+		ax := lttbX(dataPoints[selected])
+		ay := dataPoints[selected].Close
+
+		maxArea  := -1.0
+		maxIndex := currStart
+
+		for j := currStart; j < currEnd; j++ {
+			area := math.Abs((ax - avgX) * (dataPoints[j].Close - ay) - (ax - lttbX(dataPoints[j])) * (avgY - ay))
+			if area > maxArea {
+				maxArea  = area
+				maxIndex = j
+			}
+		}
+
+		indexes  = append(indexes, maxIndex)
+		selected = maxIndex
+	}
+
+	indexes = append(indexes, size -1)
+
+	list := make([]*ds.DataPoint, len(indexes))
+	for i, index := range indexes {
+		list[i] = dataPoints[index]
+	}
+
+	return list, indexes
+}
+
+//=============================================================================
+//--- Splits the bars into evenly sized buckets and builds an OHLC bar for each
+//--- one. As bar times mark the end of the bar, the reduced bar takes the time
+//--- of the last bar so that it spans the whole bucket
+
+func reduceMinMax(dataPoints []*ds.DataPoint, reduction int) ([]*ds.DataPoint, []int) {
+	size    := len(dataPoints)
+	list    := make([]*ds.DataPoint, 0, reduction)
+	indexes := make([]int,           0, reduction)
+
+	for i := 0; i < reduction; i++ {
+		start := i     * size / reduction
+		end   := (i+1) * size / reduction
+
+		if start < end {
+			list    = append(list, mergeBucket(dataPoints[start:end]))
+			indexes = append(indexes, end -1)
+		}
+	}
+
+	return list, indexes
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func mergeBucket(bucket []*ds.DataPoint) *ds.DataPoint {
+	first := bucket[0]
+	last  := bucket[len(bucket) -1]
+
+	newDp := &ds.DataPoint{
+		Time        : last.Time,
+		Open        : first.Open,
+		High        : first.High,
+		Low         : first.Low,
+		Close       : last.Close,
+		OpenInterest: last.OpenInterest,
+	}
+
+	for _, dp := range bucket {
+		newDp.High        = math.Max(newDp.High, dp.High)
+		newDp.Low         = math.Min(newDp.Low,  dp.Low)
+		newDp.UpVolume   += dp.UpVolume
+		newDp.DownVolume += dp.DownVolume
+		newDp.UpTicks    += dp.UpTicks
+		newDp.DownTicks  += dp.DownTicks
+	}
+
+	return newDp
+}
+
+//=============================================================================
+
+func lttbX(dp *ds.DataPoint) float64 {
+	return float64(dp.Time.Unix())
+}
+
+//=============================================================================
@@ -0,0 +1,143 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bit-fever/data-collector/pkg/ds"
+)
+
+//=============================================================================
+
+var reductionBase = time.Date(2024,1,2,0,0,0,0, time.UTC)
+
+//--- Flat bars at 100 with a spike and a dip
+
+func newReductionBars(size int, spikes map[int]float64) []*ds.DataPoint {
+	var list []*ds.DataPoint
+
+	for i := 0; i < size; i++ {
+		price, found := spikes[i]
+		if !found {
+			price = 100
+		}
+
+		list = append(list, &ds.DataPoint{
+			Time    : reductionBase.Add(time.Duration(i) * time.Hour),
+			Open    : price,
+			High    : price +1,
+			Low     : price -1,
+			Close   : price,
+			UpVolume: 10,
+		})
+	}
+
+	return list
+}
+
+//=============================================================================
+
+func TestReduceMinMax(t *testing.T) {
+	bars := newReductionBars(10, map[int]float64{ 1:120, 4:80 })
+
+	list, indexes := reduceMinMax(bars, 3)
+
+	if !slices.Equal(indexes, []int{ 2, 5, 9 }) {
+		t.Fatalf("Expected indexes [2 5 9] but got %v", indexes)
+	}
+
+	tests := []struct {
+		open, high, low, close float64
+		volume int
+	}{
+		{100, 121, 99, 100, 30},
+		{100, 101, 79, 100, 30},
+		{100, 101, 99, 100, 40},
+	}
+
+	for i, tt := range tests {
+		dp := list[i]
+		if dp.Open != tt.open || dp.High != tt.high || dp.Low != tt.low || dp.Close != tt.close || dp.UpVolume != tt.volume {
+			t.Errorf("Bucket %v: expected %v but got %v", i, tt, dp)
+		}
+
+		if !dp.Time.Equal(bars[indexes[i]].Time) {
+			t.Errorf("Bucket %v: time must be the one of the last bar. Got %v", i, dp.Time)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestReduceLttb(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int
+		spikes    map[int]float64
+		reduction int
+		indexes   []int
+	}{
+		{"Spike and dip are kept", 12, map[int]float64{ 3:200, 8:0 }, 4, []int{ 0, 3, 8, 11 }},
+		{"Only the borders",       12, map[int]float64{ 3:200 },      2, []int{ 0, 11 }},
+	}
+
+	for _, tt := range tests {
+		bars := newReductionBars(tt.size, tt.spikes)
+
+		list, indexes := reduceLttb(bars, tt.reduction)
+		if !slices.Equal(indexes, tt.indexes) {
+			t.Errorf("%v: expected indexes %v but got %v", tt.name, tt.indexes, indexes)
+			continue
+		}
+
+		for i, index := range indexes {
+			if list[i] != bars[index] {
+				t.Errorf("%v: reduced bars must be the source ones", tt.name)
+			}
+		}
+	}
+}
+
+//=============================================================================
+//--- Indicators are sampled at the bar that gives the close of the bucket
+
+func TestReduceMerge(t *testing.T) {
+	bars := newReductionBars(10, nil)
+
+	list, indexes := reduceMerge(bars, 3)
+
+	if !slices.Equal(indexes, []int{ 3, 7, 9 }) {
+		t.Fatalf("Expected indexes [3 7 9] but got %v", indexes)
+	}
+
+	if !list[0].Time.Equal(bars[0].Time) || list[0].UpVolume != 40 {
+		t.Errorf("Unexpected first bar: %v", list[0])
+	}
+}
+
+//=============================================================================
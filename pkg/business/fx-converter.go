@@ -0,0 +1,195 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"sort"
+	"time"
+
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/data-collector/pkg/db"
+	"github.com/bit-fever/data-collector/pkg/ds"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+//--- How far before the first bar we look for an FX rate
+const FxWarmup = 7 * 24 * time.Hour
+
+//=============================================================================
+//===
+//=== FxSource
+//===
+//=============================================================================
+
+type FxSource struct {
+	From   string
+	To     string
+	invert bool
+	config *DataConfig
+}
+
+//=============================================================================
+//--- Sets the FX source to convert the instrument's prices into the given currency.
+//--- Nothing is done if the currency is empty or the same of the instrument
+
+func SetCurrencyConversion(tx *gorm.DB, c *auth.Context, config *DataConfig, currency string) error {
+	if currency == "" || currency == config.CurrencyCode {
+		return nil
+	}
+
+	fx, err := getFxSource(tx, c, config.CurrencyCode, currency)
+	if err == nil {
+		config.Fx = fx
+	}
+
+	return err
+}
+
+//=============================================================================
+//--- Loads the FX series using the same timeframe and aggregation of the data
+
+func (s *FxSource) newConverter(params *DataInstrumentDataParams, timeframe string) (*FxConverter, error) {
+	s.config.DataConfig.Timeframe = timeframe
+
+	fxParams := &DataInstrumentDataParams{
+		Location  : params.Location,
+		From      : params.From.Add(-FxWarmup),
+		To        : params.To,
+		Aggregator: params.Aggregator.NewEmptyCopy(),
+	}
+
+	points, err := getDataPoints(fxParams, s.config)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(points) == 0 {
+		return nil, req.NewBadRequestError("No FX data found to convert %v into %v", s.From, s.To)
+	}
+
+	return &FxConverter{
+		points: points,
+		invert: s.invert,
+	}, nil
+}
+
+//=============================================================================
+//===
+//=== FxConverter
+//===
+//=============================================================================
+
+type FxConverter struct {
+	points []*ds.DataPoint
+	invert bool
+}
+
+//=============================================================================
+//--- Returns the last known rate at the given time. Times before the FX series
+//--- get its first rate
+
+func (fc *FxConverter) RateAt(t time.Time) float64 {
+	idx := sort.Search(len(fc.points), func(i int) bool {
+		return fc.points[i].Time.After(t)
+	})
+
+	if idx > 0 {
+		idx--
+	}
+
+	rate := fc.points[idx].Close
+	if fc.invert {
+		return 1 / rate
+	}
+
+	return rate
+}
+
+//=============================================================================
+
+func (fc *FxConverter) ConvertBars(points []*ds.DataPoint) {
+	for _, dp := range points {
+		rate := fc.RateAt(dp.Time)
+		dp.Open  *= rate
+		dp.High  *= rate
+		dp.Low   *= rate
+		dp.Close *= rate
+	}
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+//--- Looks for the FROM/TO pair or, if missing, for the TO/FROM one
+
+func getFxSource(tx *gorm.DB, c *auth.Context, from, to string) (*FxSource, error) {
+	if from == "" {
+		return nil, req.NewBadRequestError("The currency of the instrument is unknown. Cannot convert into %v", to)
+	}
+
+	fi, invert, err := findFxInstrument(from, to, func(base, quote string) (*db.FxInstrument, error) {
+		return db.GetFxInstrumentByPair(tx, c.Session.Username, base, quote)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if fi == nil {
+		return nil, req.NewNotFoundError("No FX instrument found to convert %v into %v", from, to)
+	}
+
+	config, err := CreateDataConfig(tx, fi.DataInstrumentId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FxSource{
+		From  : from,
+		To    : to,
+		invert: invert,
+		config: config,
+	}, nil
+}
+
+//=============================================================================
+//--- Returns true as second value if the TO/FROM pair was found
+
+func findFxInstrument(from, to string, find func(base, quote string) (*db.FxInstrument, error)) (*db.FxInstrument, bool, error) {
+	fi, err := find(from, to)
+	if err != nil || fi != nil {
+		return fi, false, err
+	}
+
+	fi, err = find(to, from)
+	return fi, true, err
+}
+
+//=============================================================================
@@ -0,0 +1,101 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bit-fever/data-collector/pkg/db"
+	"github.com/bit-fever/data-collector/pkg/ds"
+)
+
+//=============================================================================
+
+var fxBase = time.Date(2024,5,6,10,0,0,0, time.UTC)
+
+var fxPoints = []*ds.DataPoint{
+	{Time:fxBase,                  Close:1.25},
+	{Time:fxBase.Add(1*time.Hour), Close:1.60},
+	{Time:fxBase.Add(2*time.Hour), Close:2.00},
+}
+
+//=============================================================================
+
+func TestFxConverterRateAt(t *testing.T) {
+	tests := []struct {
+		name   string
+		time   time.Time
+		invert bool
+		rate   float64
+	}{
+		{"Before the series",  fxBase.Add(-time.Hour),       false, 1.25},
+		{"On a bar",           fxBase.Add(time.Hour),        false, 1.60},
+		{"Between bars",       fxBase.Add(90*time.Minute),   false, 1.60},
+		{"After the series",   fxBase.Add(48*time.Hour),     false, 2.00},
+		{"Inverted on a bar",  fxBase,                       true,  0.80},
+		{"Inverted after",     fxBase.Add(150*time.Minute),  true,  0.50},
+	}
+
+	for _, tt := range tests {
+		fc := &FxConverter{ points: fxPoints, invert: tt.invert }
+
+		if rate := fc.RateAt(tt.time); rate != tt.rate {
+			t.Errorf("%v: expected rate %v but got %v", tt.name, tt.rate, rate)
+		}
+	}
+}
+
+//=============================================================================
+//--- Only EUR/USD exists: converting USD into EUR must use it inverted
+
+func TestFindFxInstrument(t *testing.T) {
+	eurUsd := &db.FxInstrument{ BaseCurrency:"EUR", QuoteCurrency:"USD" }
+
+	find := func(base, quote string) (*db.FxInstrument, error) {
+		if base == eurUsd.BaseCurrency && quote == eurUsd.QuoteCurrency {
+			return eurUsd, nil
+		}
+
+		return nil, nil
+	}
+
+	fi, invert, err := findFxInstrument("EUR", "USD", find)
+	if err != nil || fi != eurUsd || invert {
+		t.Errorf("EUR -> USD: expected EUR/USD not inverted but got %v (invert %v)", fi, invert)
+	}
+
+	fi, invert, err = findFxInstrument("USD", "EUR", find)
+	if err != nil || fi != eurUsd || !invert {
+		t.Errorf("USD -> EUR: expected EUR/USD inverted but got %v (invert %v)", fi, invert)
+	}
+
+	fi, _, err = findFxInstrument("USD", "JPY", find)
+	if err != nil || fi != nil {
+		t.Errorf("USD -> JPY: expected no instrument but got %v", fi)
+	}
+}
+
+//=============================================================================
@@ -0,0 +1,139 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/data-collector/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func GetFxInstruments(tx *gorm.DB, c *auth.Context) (*[]db.FxInstrument, error) {
+	filter := map[string]any{}
+	filter["username"] = c.Session.Username
+
+	return db.GetFxInstruments(tx, filter)
+}
+
+//=============================================================================
+
+func AddFxInstrument(tx *gorm.DB, c *auth.Context, spec *FxInstrumentSpec) (*db.FxInstrument, error) {
+	c.Log.Info("AddFxInstrument: Adding a new FX instrument", "base", spec.BaseCurrency, "quote", spec.QuoteCurrency, "diId", spec.DataInstrumentId)
+
+	if !isCurrencyCode(spec.BaseCurrency) || !isCurrencyCode(spec.QuoteCurrency) {
+		return nil, req.NewBadRequestError("Currencies must be 3 uppercase letters: %v/%v", spec.BaseCurrency, spec.QuoteCurrency)
+	}
+
+	if spec.BaseCurrency == spec.QuoteCurrency {
+		return nil, req.NewBadRequestError("Base and quote currencies must be different: %v", spec.BaseCurrency)
+	}
+
+	_, err := getDataInstrumentAndCheckAccess(tx, c, spec.DataInstrumentId, "AddFxInstrument")
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := db.GetFxInstrumentByPair(tx, c.Session.Username, spec.BaseCurrency, spec.QuoteCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	if fi != nil {
+		return nil, req.NewBadRequestError("An FX instrument already exists for pair: %v/%v", spec.BaseCurrency, spec.QuoteCurrency)
+	}
+
+	fi = &db.FxInstrument{
+		Username        : c.Session.Username,
+		BaseCurrency    : spec.BaseCurrency,
+		QuoteCurrency   : spec.QuoteCurrency,
+		DataInstrumentId: spec.DataInstrumentId,
+	}
+
+	err = db.AddFxInstrument(tx, fi)
+	if err != nil {
+		c.Log.Error("AddFxInstrument: Could not add a new FX instrument", "error", err.Error())
+		return nil, err
+	}
+
+	c.Log.Info("AddFxInstrument: FX instrument added", "id", fi.Id)
+	return fi, nil
+}
+
+//=============================================================================
+
+func DeleteFxInstrument(tx *gorm.DB, c *auth.Context, id uint) (*db.FxInstrument, error) {
+	c.Log.Info("DeleteFxInstrument: Deleting an FX instrument", "id", id)
+
+	fi, err := db.GetFxInstrumentById(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if fi == nil {
+		c.Log.Error("DeleteFxInstrument: FX instrument was not found", "id", id)
+		return nil, req.NewNotFoundError("FX instrument was not found: %v", id)
+	}
+
+	if ! c.Session.IsAdmin() {
+		if fi.Username != c.Session.Username {
+			c.Log.Error("DeleteFxInstrument: FX instrument not owned by user", "id", id)
+			return nil, req.NewForbiddenError("FX instrument is not owned by user: %v", id)
+		}
+	}
+
+	err = db.DeleteFxInstrument(tx, id)
+	if err != nil {
+		c.Log.Error("DeleteFxInstrument: Could not delete an FX instrument", "error", err.Error())
+		return nil, err
+	}
+
+	c.Log.Info("DeleteFxInstrument: FX instrument deleted", "id", id)
+	return fi, nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
+
+//=============================================================================
@@ -0,0 +1,297 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"math"
+	"time"
+
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/data-collector/pkg/core/indicator"
+	"github.com/bit-fever/data-collector/pkg/ds"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+const TradingDaysPerYear = 252
+
+//=============================================================================
+
+type InstrumentStatisticsResponse struct {
+	Id           uint                 `json:"id"`
+	Symbol       string               `json:"symbol"`
+	From         string               `json:"from"`
+	To           string               `json:"to"`
+	Timeframe    string               `json:"timeframe"`
+	Timezone     string               `json:"timezone"`
+	Bars         int                  `json:"bars"`
+	Days         int                  `json:"days"`
+	AvgTrueRange float64              `json:"avgTrueRange"`
+	Volatility   VolatilityStats      `json:"volatility"`
+	RangeByDow   [7]*DailyRangeStats  `json:"rangeByDow"`
+	RangeByMonth [12]*DailyRangeStats `json:"rangeByMonth"`
+	VolumeBySlot [48]*VolumeSlotStats `json:"volumeBySlot"`
+	Gaps         GapStats             `json:"gaps"`
+}
+
+//=============================================================================
+//--- Standard deviations of log returns
+
+type VolatilityStats struct {
+	PerBar     float64 `json:"perBar"`
+	Daily      float64 `json:"daily"`
+	Annualized float64 `json:"annualized"`
+}
+
+//=============================================================================
+
+type DailyRangeStats struct {
+	Days     int     `json:"days"`
+	AvgRange float64 `json:"avgRange"`
+}
+
+//-----------------------------------------------------------------------------
+
+func (s *DailyRangeStats) add(dr float64) {
+	s.AvgRange = (s.AvgRange * float64(s.Days) + dr) / float64(s.Days +1)
+	s.Days++
+}
+
+//=============================================================================
+
+type VolumeSlotStats struct {
+	Bars      int     `json:"bars"`
+	AvgVolume float64 `json:"avgVolume"`
+}
+
+//-----------------------------------------------------------------------------
+
+func (s *VolumeSlotStats) add(volume int) {
+	s.AvgVolume = (s.AvgVolume * float64(s.Bars) + float64(volume)) / float64(s.Bars +1)
+	s.Bars++
+}
+
+//=============================================================================
+//--- A gap is the difference between the open of a day and the close of the
+//--- previous one. It is filled if the price goes back to the previous close
+//--- during the day
+
+type GapStats struct {
+	Up         int     `json:"up"`
+	Down       int     `json:"down"`
+	AvgUp      float64 `json:"avgUp"`
+	AvgDown    float64 `json:"avgDown"`
+	UpFilled   int     `json:"upFilled"`
+	DownFilled int     `json:"downFilled"`
+}
+
+//=============================================================================
+
+func GetInstrumentStatisticsConfig(tx *gorm.DB, c *auth.Context, id uint) (*DataConfig, error) {
+	c.Log.Info("GetInstrumentStatisticsConfig: Getting data instrument", "id", id)
+
+	_, err := getDataInstrumentAndCheckAccess(tx, c, id, "GetInstrumentStatisticsConfig")
+	if err != nil {
+		return nil, err
+	}
+
+	return CreateDataConfig(tx, id)
+}
+
+//=============================================================================
+//--- Volumes and daily values are always calculated using 30m bars
+
+func GetInstrumentStatistics(c *auth.Context, spec *DataInstrumentDataSpec) (*InstrumentStatisticsResponse, error) {
+	timeframe := spec.Config.DataConfig.Timeframe
+
+	params,err := parseInstrumentDataParams(spec)
+	if err != nil {
+		return nil, req.NewBadRequestError(err.Error())
+	}
+
+	bars, err := getDataPoints(params, spec.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	slotBars := bars
+	if timeframe != "30m" {
+		spec.Config.DataConfig.Timeframe = "15m"
+		slotParams := &DataInstrumentDataParams{
+			Location  : params.Location,
+			From      : params.From,
+			To        : params.To,
+			Aggregator: ds.NewDataAggregator(ds.TimeSlotFunction30m, params.Location),
+		}
+
+		slotBars, err = getDataPoints(slotParams, spec.Config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	days := ds.NewDataAggregator(ds.TimeSlotFunction1440m, params.Location)
+	for _, dp := range slotBars {
+		days.Add(dp)
+	}
+	days.Flush()
+
+	c.Log.Info("GetInstrumentStatistics: Data loaded", "id", spec.Id, "bars", len(bars), "slotBars", len(slotBars), "days", len(days.DataPoints()))
+
+	isr := &InstrumentStatisticsResponse{
+		Id          : spec.Id,
+		Symbol      : spec.Config.DataConfig.Symbol,
+		From        : params.From.Format(time.DateTime),
+		To          : params.To.Format(time.DateTime),
+		Timeframe   : timeframe,
+		Timezone    : params.Location.String(),
+		Bars        : len(bars),
+		Days        : len(days.DataPoints()),
+		AvgTrueRange: calcAvgTrueRange(bars),
+	}
+
+	isr.Volatility.PerBar     = calcLogReturnsStdDev(bars)
+	isr.Volatility.Daily      = calcLogReturnsStdDev(days.DataPoints())
+	isr.Volatility.Annualized = isr.Volatility.Daily * math.Sqrt(TradingDaysPerYear)
+
+	calcDailyRanges(isr, days.DataPoints())
+	calcVolumeProfile(isr, slotBars)
+	calcGaps(&isr.Gaps, days.DataPoints())
+
+	return isr, nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func calcAvgTrueRange(bars []*ds.DataPoint) float64 {
+	if len(bars) == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for _, tr := range indicator.ATR(bars, 1) {
+		sum += tr
+	}
+
+	return sum / float64(len(bars))
+}
+
+//=============================================================================
+
+func calcLogReturnsStdDev(bars []*ds.DataPoint) float64 {
+	var returns []float64
+
+	for i := 1; i < len(bars); i++ {
+		if bars[i-1].Close > 0 && bars[i].Close > 0 {
+			returns = append(returns, math.Log(bars[i].Close / bars[i-1].Close))
+		}
+	}
+
+	if len(returns) < 2 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+
+	return math.Sqrt(variance / float64(len(returns) -1))
+}
+
+//=============================================================================
+//--- Daily bars' times mark the end of the day (midnight of the next one)
+
+func calcDailyRanges(isr *InstrumentStatisticsResponse, days []*ds.DataPoint) {
+	for _, dp := range days {
+		day := dp.Time.Add(-time.Minute)
+		dow := day.Weekday()
+		mon := day.Month() -1
+
+		if isr.RangeByDow[dow] == nil {
+			isr.RangeByDow[dow] = &DailyRangeStats{}
+		}
+
+		if isr.RangeByMonth[mon] == nil {
+			isr.RangeByMonth[mon] = &DailyRangeStats{}
+		}
+
+		isr.RangeByDow  [dow].add(dp.High - dp.Low)
+		isr.RangeByMonth[mon].add(dp.High - dp.Low)
+	}
+}
+
+//=============================================================================
+
+func calcVolumeProfile(isr *InstrumentStatisticsResponse, slotBars []*ds.DataPoint) {
+	for _, dp := range slotBars {
+		slotTime := getSlotTime(dp, DefaultSlotSize)
+		slot     := getSlot(slotTime.Hour(), slotTime.Minute(), DefaultSlotSize)
+
+		if isr.VolumeBySlot[slot] == nil {
+			isr.VolumeBySlot[slot] = &VolumeSlotStats{}
+		}
+
+		isr.VolumeBySlot[slot].add(dp.UpVolume + dp.DownVolume)
+	}
+}
+
+//=============================================================================
+
+func calcGaps(gs *GapStats, days []*ds.DataPoint) {
+	for i := 1; i < len(days); i++ {
+		prevClose := days[i-1].Close
+		curr      := days[i]
+		gap       := curr.Open - prevClose
+
+		if gap > 0 {
+			gs.AvgUp = (gs.AvgUp * float64(gs.Up) + gap) / float64(gs.Up +1)
+			gs.Up++
+			if curr.Low <= prevClose {
+				gs.UpFilled++
+			}
+		} else if gap < 0 {
+			gs.AvgDown = (gs.AvgDown * float64(gs.Down) - gap) / float64(gs.Down +1)
+			gs.Down++
+			if curr.High >= prevClose {
+				gs.DownFilled++
+			}
+		}
+	}
+}
+
+//=============================================================================
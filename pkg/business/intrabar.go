@@ -0,0 +1,124 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"sort"
+	"time"
+
+	"github.com/bit-fever/data-collector/pkg/ds"
+)
+
+//=============================================================================
+//--- Provides the 1m bars inside slots. Bars are loaded one month at a time
+//--- and older months are dropped, as backtests walk the history forward. A
+//--- loading error stops further queries and is reported at the end of the backtest
+
+type minuteSource struct {
+	config   *DataConfig
+	loc      *time.Location
+	adjustTo  time.Time
+	months    map[time.Time][]*ds.DataPoint
+	err       error
+}
+
+//=============================================================================
+
+func newMinuteSource(bbr *BiasBacktestResponse) *minuteSource {
+	if !bbr.Spec.IntrabarResolution && !bbr.Spec.EntryAtOpen {
+		return nil
+	}
+
+	loc,_ := time.LoadLocation(bbr.config.Timezone)
+
+	config := *bbr.config
+	config.DataConfig.Timeframe = "1m"
+
+	return &minuteSource{
+		config  : &config,
+		loc     : loc,
+		adjustTo: bbr.Spec.to,
+		months  : map[time.Time][]*ds.DataPoint{},
+	}
+}
+
+//=============================================================================
+//--- Returns the bars with from < time <= to, as bar times mark their end
+
+func (ms *minuteSource) getBars(from, to time.Time) []*ds.DataPoint {
+	y,m,_ := from.In(ms.loc).Date()
+	month := time.Date(y, m, 1, 0, 0, 0, 0, ms.loc)
+
+	bars, ok := ms.months[month]
+	if !ok {
+		if ms.err != nil {
+			return nil
+		}
+
+		for m := range ms.months {
+			if m.Before(month) {
+				delete(ms.months, m)
+			}
+		}
+
+		bars = ms.load(month)
+		ms.months[month] = bars
+	}
+
+	start := sort.Search(len(bars), func(i int) bool {
+		return bars[i].Time.After(from)
+	})
+
+	end := sort.Search(len(bars), func(i int) bool {
+		return bars[i].Time.After(to)
+	})
+
+	return bars[start:end]
+}
+
+//=============================================================================
+//===
+//=== Private methods
+//===
+//=============================================================================
+//--- One day more is loaded, so that slots ending at midnight are complete. Bars
+//--- are adjusted like the slots, that are loaded up to the end of the backtest
+
+func (ms *minuteSource) load(month time.Time) []*ds.DataPoint {
+	params := newDefaultDataParams(ms.loc, ds.NewDataAggregator(nil, ms.loc))
+	params.From     = month
+	params.To       = month.AddDate(0, 1, 1)
+	params.AdjustTo = ms.adjustTo
+
+	bars, err := getDataPoints(params, ms.config)
+	if err != nil {
+		ms.err = err
+		return nil
+	}
+
+	return bars
+}
+
+//=============================================================================
@@ -28,6 +28,8 @@ import (
 	"time"
 
 	"github.com/bit-fever/data-collector/pkg/core"
+	"github.com/bit-fever/data-collector/pkg/core/indicator"
+	"github.com/bit-fever/data-collector/pkg/core/synthetic"
 	"github.com/bit-fever/data-collector/pkg/db"
 	"github.com/bit-fever/data-collector/pkg/ds"
 )
@@ -57,38 +59,57 @@ type DatafileUploadResponse struct {
 //=============================================================================
 
 type DataInstrumentDataSpec struct {
-	Id        uint
-	From      string
-	To        string
-	Timezone  string
-	Reduction string
-	Config    *DataConfig
+	Id         uint
+	From       string
+	To         string
+	Timezone   string
+	Reduction  string
+	Algorithm  string
+	Indicators string
+	Config     *DataConfig
 }
 
 //=============================================================================
+//--- For virtual instruments, prices are back-adjusted up to AdjustTo, or up to
+//--- To when not set
 
 type DataInstrumentDataParams struct {
 	Location  *time.Location
 	From       time.Time
 	To         time.Time
 	Reduction  int
+	Algorithm  string
+	Indicators []*indicator.Spec
+	Warmup     time.Duration
 	Aggregator *ds.DataAggregator
+	AdjustTo   time.Time
 }
 
 //=============================================================================
 
 type DataInstrumentDataResponse struct {
-	Id               uint            `json:"id"`
-	Symbol           string          `json:"symbol"`
-	From             string          `json:"from"`
-	To               string          `json:"to"`
-	Timeframe        string          `json:"timeframe"`
-	Timezone         string          `json:"timezone"`
-	Reduction        int             `json:"reduction,omitempty"`
-	Reduced          bool            `json:"reduced"`
-	Records          int             `json:"records"`
-	NoDataForVirtual bool            `json:"noDataForVirtual"`
-	DataPoints       []*ds.DataPoint `json:"dataPoints"`
+	Id               uint               `json:"id"`
+	Symbol           string             `json:"symbol"`
+	From             string             `json:"from"`
+	To               string             `json:"to"`
+	Timeframe        string             `json:"timeframe"`
+	Timezone         string             `json:"timezone"`
+	Reduction        int                `json:"reduction,omitempty"`
+	Reduced          bool               `json:"reduced"`
+	Algorithm        string             `json:"algorithm,omitempty"`
+	Records          int                `json:"records"`
+	NoDataForVirtual bool               `json:"noDataForVirtual"`
+	Currency         string             `json:"currency,omitempty"`
+	Adjustment       string             `json:"adjustment,omitempty"`
+	DataPoints       []*ds.DataPoint    `json:"dataPoints"`
+	Indicators       []*IndicatorSeries `json:"indicators,omitempty"`
+}
+
+//=============================================================================
+
+type IndicatorSeries struct {
+	Name   string     `json:"name"`
+	Values []*float64 `json:"values"`
 }
 
 //=============================================================================
@@ -97,6 +118,78 @@ type DataInstrumentExt struct {
 	db.DataInstrument
 }
 
+//=============================================================================
+//=== Roll overrides
+//=============================================================================
+
+type RollOverrideSpec struct {
+	DataInstrumentId  uint      `json:"dataInstrumentId" binding:"required"`
+	RolloverDate      time.Time `json:"rolloverDate"     binding:"required"`
+	Notes             string    `json:"notes"`
+}
+
+//=============================================================================
+
+type CorporateActionSpec struct {
+	ExDate            time.Time `json:"exDate"           binding:"required"`
+	Type              db.CAType `json:"type"             binding:"required"`
+	Value             float64   `json:"value"            binding:"required"`
+	Notes             string    `json:"notes"`
+}
+
+//=============================================================================
+
+type CorporateActionUploadResponse struct {
+	Added   int `json:"added"`
+	Skipped int `json:"skipped"`
+}
+
+//=============================================================================
+
+type ManualRolloverSpec struct {
+	Manual            bool       `json:"manual"`
+	RolloverDate     *time.Time  `json:"rolloverDate"`
+	RolloverDelta     float64    `json:"rolloverDelta"`
+}
+
+//=============================================================================
+
+type RollSettingsSpec struct {
+	Estimator  db.DPRollEstimator `json:"estimator" binding:"required"`
+	Timeframe  string             `json:"timeframe" binding:"required"`
+}
+
+//=============================================================================
+//=== Virtual instruments
+//=============================================================================
+
+type VirtualInstrumentSpec struct {
+	Symbol       string           `json:"symbol"      binding:"required"`
+	Name         string           `json:"name"`
+	Months       string           `json:"months"      binding:"required"`
+	RollTrigger  db.DPRollTrigger `json:"rollTrigger" binding:"required"`
+}
+
+//=============================================================================
+//=== Synthetic instruments
+//=============================================================================
+
+type SyntheticInstrumentSpec struct {
+	Symbol       string `json:"symbol"     binding:"required"`
+	Name         string `json:"name"`
+	Expression   string `json:"expression" binding:"required"`
+}
+
+//=============================================================================
+//=== FX instruments
+//=============================================================================
+
+type FxInstrumentSpec struct {
+	BaseCurrency      string `json:"baseCurrency"     binding:"required"`
+	QuoteCurrency     string `json:"quoteCurrency"    binding:"required"`
+	DataInstrumentId  uint   `json:"dataInstrumentId" binding:"required"`
+}
+
 //=============================================================================
 //=== Bias analysis
 //=============================================================================
@@ -106,6 +199,7 @@ type BiasAnalysisSpec struct {
 	BrokerProductId   uint    `json:"brokerProductId"`
 	Name              string  `json:"name"`
 	Notes             string  `json:"notes"`
+	SlotSize          int16   `json:"slotSize"`
 }
 
 //=============================================================================
@@ -179,6 +273,11 @@ type DataConfig struct {
 	Timezone           string
 	VirtualInstrument  bool
 	Instruments       *[]db.DataInstrument
+	Synthetic         *synthetic.Expression
+	Legs               map[string]*DataConfig
+	CurrencyCode       string
+	Fx                *FxSource
+	Adjustment        *PriceAdjustment
 }
 
 //=============================================================================
@@ -0,0 +1,238 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+
+	"github.com/bit-fever/data-collector/pkg/core"
+)
+
+//=============================================================================
+
+const (
+	MonteCarloShuffle   = "shuffle"
+	MonteCarloBootstrap = "bootstrap"
+
+	DefaultMonteCarloIterations = 1000
+	MaxMonteCarloIterations     = 10000
+
+	//--- Equity bands are sampled to limit the memory used by the iterations
+	MaxMonteCarloEquityPoints = 250
+)
+
+//=============================================================================
+//--- Shuffle reorders the trades, so only drawdowns change. Bootstrap draws
+//--- trades with replacement. Ruin is computed only if a capital is given
+
+type MonteCarloSpec struct {
+	Method     string  `json:"method"`
+	Iterations int     `json:"iterations"`
+	Seed       uint64  `json:"seed"`
+	Capital    float64 `json:"capital"`
+}
+
+//=============================================================================
+
+type MonteCarloResult struct {
+	Method          string         `json:"method"`
+	Iterations      int            `json:"iterations"`
+	EquityIndexes   []int          `json:"equityIndexes"`
+	EquityBands     []*Percentiles `json:"equityBands"`
+	MaxDrawdown     *Percentiles   `json:"maxDrawdown"`
+	FinalProfit     *Percentiles   `json:"finalProfit"`
+	RuinProbability float64        `json:"ruinProbability"`
+}
+
+//=============================================================================
+
+type Percentiles struct {
+	P5  float64 `json:"p5"`
+	P25 float64 `json:"p25"`
+	P50 float64 `json:"p50"`
+	P75 float64 `json:"p75"`
+	P95 float64 `json:"p95"`
+}
+
+//=============================================================================
+
+func NewMonteCarloResult(trades []*BiasTrade, spec *MonteCarloSpec) *MonteCarloResult {
+	mcr := &MonteCarloResult{
+		Method     : spec.Method,
+		Iterations : spec.Iterations,
+		EquityBands: []*Percentiles{},
+	}
+
+	numTrades := len(trades)
+	if numTrades == 0 {
+		return mcr
+	}
+
+	profits := make([]float64, numTrades)
+	for i, bt := range trades {
+		profits[i] = bt.NetProfit
+	}
+
+	mcr.EquityIndexes = calcEquityIndexes(numTrades)
+
+	rnd      := rand.New(rand.NewPCG(spec.Seed, spec.Seed))
+	sample   := make([]float64, numTrades)
+	equities := make([][]float64, len(mcr.EquityIndexes))
+	drawdowns:= make([]float64, spec.Iterations)
+	finals   := make([]float64, spec.Iterations)
+	ruins    := 0
+
+	for i := range equities {
+		equities[i] = make([]float64, spec.Iterations)
+	}
+
+	for it := 0; it < spec.Iterations; it++ {
+		if spec.Method == MonteCarloBootstrap {
+			for i := range sample {
+				sample[i] = profits[rnd.IntN(numTrades)]
+			}
+		} else {
+			copy(sample, profits)
+			rnd.Shuffle(numTrades, func(i, j int) {
+				sample[i], sample[j] = sample[j], sample[i]
+			})
+		}
+
+		equity, peak, maxDd, ruined, next := 0.0, 0.0, 0.0, false, 0
+
+		for i, profit := range sample {
+			equity += profit
+			peak     = max(peak,  equity)
+			maxDd    = max(maxDd, peak - equity)
+
+			if spec.Capital > 0 && spec.Capital + equity <= 0 {
+				ruined = true
+			}
+
+			if next < len(mcr.EquityIndexes) && mcr.EquityIndexes[next] == i {
+				equities[next][it] = equity
+				next++
+			}
+		}
+
+		drawdowns[it] = maxDd
+		finals   [it] = equity
+
+		if ruined {
+			ruins++
+		}
+	}
+
+	for _, values := range equities {
+		mcr.EquityBands = append(mcr.EquityBands, NewPercentiles(values))
+	}
+
+	mcr.MaxDrawdown     = NewPercentiles(drawdowns)
+	mcr.FinalProfit     = NewPercentiles(finals)
+	mcr.RuinProbability = core.Trunc2d(float64(ruins) * 100 / float64(spec.Iterations))
+
+	return mcr
+}
+
+//=============================================================================
+
+func NewPercentiles(values []float64) *Percentiles {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	return &Percentiles{
+		P5 : math.Trunc(percentile(sorted, 0.05)),
+		P25: math.Trunc(percentile(sorted, 0.25)),
+		P50: math.Trunc(percentile(sorted, 0.50)),
+		P75: math.Trunc(percentile(sorted, 0.75)),
+		P95: math.Trunc(percentile(sorted, 0.95)),
+	}
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func checkMonteCarloSpec(mcs *MonteCarloSpec) error {
+	if mcs.Method == "" {
+		mcs.Method = MonteCarloShuffle
+	}
+
+	if mcs.Method != MonteCarloShuffle && mcs.Method != MonteCarloBootstrap {
+		return errors.New("monteCarlo method must be shuffle or bootstrap: "+ mcs.Method)
+	}
+
+	if mcs.Iterations == 0 {
+		mcs.Iterations = DefaultMonteCarloIterations
+	}
+
+	if mcs.Iterations < 0 || mcs.Iterations > MaxMonteCarloIterations {
+		return errors.New("monteCarlo iterations must be in the range 1.."+ strconv.Itoa(MaxMonteCarloIterations))
+	}
+
+	if mcs.Capital < 0 {
+		return errors.New("monteCarlo capital cannot be negative")
+	}
+
+	return nil
+}
+
+//=============================================================================
+//--- Indexes of the trades where the equity bands are calculated. The last
+//--- trade is always included
+
+func calcEquityIndexes(numTrades int) []int {
+	points  := min(numTrades, MaxMonteCarloEquityPoints)
+	indexes := make([]int, points)
+
+	for k := range indexes {
+		indexes[k] = (k+1) * numTrades / points -1
+	}
+
+	return indexes
+}
+
+//=============================================================================
+//--- Linear interpolation between the closest ranks
+
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	pos  := p * float64(len(sorted) -1)
+	low  := int(math.Floor(pos))
+	high := int(math.Ceil (pos))
+
+	return sorted[low] + (sorted[high] - sorted[low]) * (pos - float64(low))
+}
+
+//=============================================================================
@@ -0,0 +1,142 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"testing"
+)
+
+//=============================================================================
+
+func newProfitTrades(profits ...float64) []*BiasTrade {
+	var trades []*BiasTrade
+	for _, p := range profits {
+		trades = append(trades, &BiasTrade{ NetProfit: p })
+	}
+
+	return trades
+}
+
+//=============================================================================
+//--- With 2 losses of 50 and 30, the max drawdown goes from 50 (apart) to 80
+//--- (one after the other)
+
+func TestMonteCarloShuffle(t *testing.T) {
+	trades := newProfitTrades(100, -50, 200, -30)
+
+	mcr := NewMonteCarloResult(trades, &MonteCarloSpec{ Method: MonteCarloShuffle, Iterations: 500, Seed: 7 })
+
+	if fp := mcr.FinalProfit; fp.P5 != 220 || fp.P95 != 220 {
+		t.Errorf("Shuffling must not change the final profit: %+v", fp)
+	}
+
+	if dd := mcr.MaxDrawdown; dd.P5 < 50 || dd.P95 > 80 || dd.P5 == dd.P95 {
+		t.Errorf("Max drawdown out of the [50, 80] range: %+v", dd)
+	}
+
+	if len(mcr.EquityIndexes) != 4 || len(mcr.EquityBands) != 4 || mcr.EquityBands[3].P50 != 220 {
+		t.Errorf("Expected 4 equity bands ending at 220 but got %v", len(mcr.EquityBands))
+	}
+
+	if mcr.RuinProbability != 0 {
+		t.Errorf("Ruin must be 0 without a capital but got %v", mcr.RuinProbability)
+	}
+}
+
+//=============================================================================
+
+func TestMonteCarloBootstrap(t *testing.T) {
+	trades := newProfitTrades(100, -50, 200, -30)
+	spec   := &MonteCarloSpec{ Method: MonteCarloBootstrap, Iterations: 500, Seed: 7, Capital: 40 }
+
+	mcr1 := NewMonteCarloResult(trades, spec)
+	mcr2 := NewMonteCarloResult(trades, spec)
+
+	if *mcr1.FinalProfit != *mcr2.FinalProfit || mcr1.RuinProbability != mcr2.RuinProbability {
+		t.Errorf("Results must be repeatable with the same seed: %+v != %+v", mcr1.FinalProfit, mcr2.FinalProfit)
+	}
+
+	if fp := mcr1.FinalProfit; fp.P5 < -200 || fp.P95 > 800 || fp.P5 == fp.P95 {
+		t.Errorf("Final profit out of the [-200, 800] range: %+v", fp)
+	}
+
+	if mcr1.RuinProbability <= 0 || mcr1.RuinProbability >= 100 {
+		t.Errorf("Expected a ruin probability between 0 and 100 but got %v", mcr1.RuinProbability)
+	}
+}
+
+//=============================================================================
+
+func TestMonteCarloWithoutTrades(t *testing.T) {
+	mcr := NewMonteCarloResult(nil, &MonteCarloSpec{ Method: MonteCarloShuffle, Iterations: 100 })
+
+	if len(mcr.EquityBands) != 0 || mcr.MaxDrawdown != nil || mcr.FinalProfit != nil {
+		t.Errorf("Expected an empty result but got %+v", mcr)
+	}
+}
+
+//=============================================================================
+
+func TestCalcEquityIndexes(t *testing.T) {
+	tests := []struct {
+		numTrades int
+		points    int
+		first     int
+	}{
+		{   1,   1,  0},
+		{ 100, 100,  0},
+		{1000, 250,  3},
+	}
+
+	for _, tt := range tests {
+		indexes := calcEquityIndexes(tt.numTrades)
+
+		if len(indexes) != tt.points || indexes[0] != tt.first || indexes[len(indexes) -1] != tt.numTrades -1 {
+			t.Errorf("%v trades: unexpected indexes %v", tt.numTrades, indexes)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{ 10, 20, 30, 40, 50 }
+
+	tests := map[float64]float64{
+		0.00: 10,
+		0.25: 20,
+		0.50: 30,
+		0.60: 34,
+		1.00: 50,
+	}
+
+	for p, expected := range tests {
+		if value := percentile(sorted, p); !isNear(value, expected) {
+			t.Errorf("Percentile %v: expected %v but got %v", p, expected, value)
+		}
+	}
+}
+
+//=============================================================================
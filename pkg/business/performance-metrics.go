@@ -0,0 +1,302 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/bit-fever/data-collector/pkg/core"
+)
+
+//=============================================================================
+//--- Drawdowns are absolute values in the profit currency. The ulcer index uses
+//--- them instead of percentages, as backtests have no starting capital.
+//--- Sharpe and Sortino are annualized from the daily P&L of all weekdays
+//--- between the first and the last trade
+
+type PerformanceMetrics struct {
+	NumTrades       int          `json:"numTrades"`
+	Winners         int          `json:"winners"`
+	Losers          int          `json:"losers"`
+	WinRate         float64      `json:"winRate"`
+	ProfitFactor    float64      `json:"profitFactor"`
+	AvgWin          float64      `json:"avgWin"`
+	AvgLoss         float64      `json:"avgLoss"`
+	LargestWin      float64      `json:"largestWin"`
+	LargestLoss     float64      `json:"largestLoss"`
+	MaxConsecWins   int          `json:"maxConsecWins"`
+	MaxConsecLosses int          `json:"maxConsecLosses"`
+	MaxDrawdown     float64      `json:"maxDrawdown"`
+	MaxDrawdownDays int          `json:"maxDrawdownDays"`
+	SharpeRatio     float64      `json:"sharpeRatio"`
+	SortinoRatio    float64      `json:"sortinoRatio"`
+	UlcerIndex      float64      `json:"ulcerIndex"`
+	YearlyPnl       []*PeriodPnl `json:"yearlyPnl"`
+	MonthlyPnl      []*PeriodPnl `json:"monthlyPnl"`
+}
+
+//=============================================================================
+//--- Month is 0 for yearly values
+
+type PeriodPnl struct {
+	Year      int     `json:"year"`
+	Month     int     `json:"month,omitempty"`
+	NetProfit float64 `json:"netProfit"`
+	NumTrades int     `json:"numTrades"`
+}
+
+//=============================================================================
+//--- Trades must be sorted by exit time
+
+func NewPerformanceMetrics(trades []*BiasTrade) *PerformanceMetrics {
+	pm := &PerformanceMetrics{
+		NumTrades : len(trades),
+		YearlyPnl : []*PeriodPnl{},
+		MonthlyPnl: []*PeriodPnl{},
+	}
+
+	if len(trades) == 0 {
+		return pm
+	}
+
+	pm.calcTradeStats(trades)
+	pm.calcDrawdown(trades)
+	pm.calcPeriodPnl(trades)
+	pm.calcRatios(trades)
+
+	return pm
+}
+
+//=============================================================================
+//===
+//=== Private methods
+//===
+//=============================================================================
+
+func (pm *PerformanceMetrics) calcTradeStats(trades []*BiasTrade) {
+	grossWin, grossLoss := 0.0, 0.0
+	consecWins, consecLosses := 0, 0
+
+	for _, bt := range trades {
+		if bt.NetProfit > 0 {
+			pm.Winners++
+			grossWin += bt.NetProfit
+			consecWins++
+			consecLosses = 0
+		} else {
+			pm.Losers++
+			grossLoss += bt.NetProfit
+			consecLosses++
+			consecWins = 0
+		}
+
+		pm.LargestWin      = max(pm.LargestWin,      bt.NetProfit)
+		pm.LargestLoss     = min(pm.LargestLoss,     bt.NetProfit)
+		pm.MaxConsecWins   = max(pm.MaxConsecWins,   consecWins)
+		pm.MaxConsecLosses = max(pm.MaxConsecLosses, consecLosses)
+	}
+
+	pm.WinRate = core.Trunc2d(float64(pm.Winners) * 100 / float64(pm.NumTrades))
+
+	if pm.Winners > 0 {
+		pm.AvgWin = core.Trunc2d(grossWin / float64(pm.Winners))
+	}
+
+	if pm.Losers > 0 {
+		pm.AvgLoss = core.Trunc2d(grossLoss / float64(pm.Losers))
+	}
+
+	if grossLoss < 0 {
+		pm.ProfitFactor = core.Trunc2d(grossWin / -grossLoss)
+	}
+}
+
+//=============================================================================
+//--- The duration is the longest period from an equity peak to the trade that
+//--- recovers it (or to the last trade)
+
+func (pm *PerformanceMetrics) calcDrawdown(trades []*BiasTrade) {
+	equity, peak := 0.0, 0.0
+	peakTime     := trades[0].EntryTime
+	maxDuration  := time.Duration(0)
+	underwater   := false
+
+	for _, bt := range trades {
+		equity += bt.NetProfit
+
+		if equity >= peak {
+			if underwater {
+				maxDuration = max(maxDuration, bt.ExitTime.Sub(peakTime))
+				underwater  = false
+			}
+
+			peak     = equity
+			peakTime = bt.ExitTime
+		} else {
+			underwater     = true
+			pm.MaxDrawdown = max(pm.MaxDrawdown, peak - equity)
+			maxDuration    = max(maxDuration,    bt.ExitTime.Sub(peakTime))
+		}
+	}
+
+	pm.MaxDrawdown     = math.Trunc(pm.MaxDrawdown)
+	pm.MaxDrawdownDays = int(maxDuration.Hours() / 24)
+}
+
+//=============================================================================
+
+func (pm *PerformanceMetrics) calcPeriodPnl(trades []*BiasTrade) {
+	var year, month *PeriodPnl
+
+	for _, bt := range trades {
+		y,m,_ := bt.ExitTime.Date()
+
+		if year == nil || year.Year != y {
+			year = &PeriodPnl{ Year: y }
+			pm.YearlyPnl = append(pm.YearlyPnl, year)
+		}
+
+		if month == nil || month.Year != y || month.Month != int(m) {
+			month = &PeriodPnl{ Year: y, Month: int(m) }
+			pm.MonthlyPnl = append(pm.MonthlyPnl, month)
+		}
+
+		year .NetProfit += bt.NetProfit
+		month.NetProfit += bt.NetProfit
+		year .NumTrades++
+		month.NumTrades++
+	}
+
+	for _, list := range [][]*PeriodPnl{ pm.YearlyPnl, pm.MonthlyPnl } {
+		for _, pp := range list {
+			pp.NetProfit = math.Trunc(pp.NetProfit)
+		}
+	}
+}
+
+//=============================================================================
+
+func (pm *PerformanceMetrics) calcRatios(trades []*BiasTrade) {
+	daily := calcDailyPnl(trades)
+	if len(daily) < 2 {
+		return
+	}
+
+	sum, sumDown := 0.0, 0.0
+	for _, pnl := range daily {
+		sum += pnl
+		if pnl < 0 {
+			sumDown += pnl * pnl
+		}
+	}
+
+	mean     := sum / float64(len(daily))
+	variance := 0.0
+	for _, pnl := range daily {
+		variance += (pnl - mean) * (pnl - mean)
+	}
+
+	stdDev   := math.Sqrt(variance / float64(len(daily) -1))
+	downDev  := math.Sqrt(sumDown  / float64(len(daily)))
+	annFactor:= math.Sqrt(TradingDaysPerYear)
+
+	if stdDev > 0 {
+		pm.SharpeRatio = core.Trunc2d(mean / stdDev * annFactor)
+	}
+
+	if downDev > 0 {
+		pm.SortinoRatio = core.Trunc2d(mean / downDev * annFactor)
+	}
+
+	//--- Ulcer index on the daily equity
+
+	equity, peak, sumSq := 0.0, 0.0, 0.0
+	for _, pnl := range daily {
+		equity += pnl
+		peak    = max(peak, equity)
+		sumSq  += (peak - equity) * (peak - equity)
+	}
+
+	pm.UlcerIndex = core.Trunc2d(math.Sqrt(sumSq / float64(len(daily))))
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+//--- Returns the P&L of each day from the first to the last exit date, with zero
+//--- for weekdays without trades. Weekend days are kept only if trades exited
+//--- on them (i.e. Sunday evening sessions)
+
+func calcDailyPnl(trades []*BiasTrade) []float64 {
+	pnlByDay := map[time.Time]float64{}
+
+	for _, bt := range trades {
+		pnlByDay[truncToDay(bt.ExitTime)] += bt.NetProfit
+	}
+
+	var daily []float64
+
+	last := truncToDay(trades[len(trades) -1].ExitTime)
+
+	for day := truncToDay(trades[0].ExitTime); !day.After(last); day = day.AddDate(0, 0, 1) {
+		pnl, found := pnlByDay[day]
+
+		if found || (day.Weekday() != time.Saturday && day.Weekday() != time.Sunday) {
+			daily = append(daily, pnl)
+		}
+	}
+
+	return daily
+}
+
+//=============================================================================
+
+func truncToDay(t time.Time) time.Time {
+	y,m,d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+//=============================================================================
+//--- Trades of different configs are merged by exit time
+
+func mergeTrades(configs []*BacktestedConfig) []*BiasTrade {
+	var trades []*BiasTrade
+
+	for _, btc := range configs {
+		trades = append(trades, btc.Trades...)
+	}
+
+	slices.SortStableFunc(trades, func(a, b *BiasTrade) int {
+		return a.ExitTime.Compare(b.ExitTime)
+	})
+
+	return trades
+}
+
+//=============================================================================
@@ -0,0 +1,130 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"testing"
+	"time"
+)
+
+//=============================================================================
+
+func newMetricsTrade(y int, m time.Month, d int, profit float64) *BiasTrade {
+	exit := time.Date(y, m, d, 15, 0, 0, 0, time.UTC)
+
+	return &BiasTrade{
+		EntryTime: exit.Add(-time.Hour),
+		ExitTime : exit,
+		NetProfit: profit,
+	}
+}
+
+//-----------------------------------------------------------------------------
+//--- The 4th trade exits on Sunday, like in evening futures sessions
+
+var metricsTrades = []*BiasTrade{
+	newMetricsTrade(2024,3, 4, 100),
+	newMetricsTrade(2024,3, 5, -50),
+	newMetricsTrade(2024,3, 6, -30),
+	newMetricsTrade(2024,3,10, 200),
+	newMetricsTrade(2024,4, 1,  20),
+}
+
+//=============================================================================
+
+func TestPerformanceMetricsTradeStats(t *testing.T) {
+	pm := NewPerformanceMetrics(metricsTrades)
+
+	tests := []struct {
+		name     string
+		value    float64
+		expected float64
+	}{
+		{"winners",           float64(pm.Winners),         3     },
+		{"losers",            float64(pm.Losers),          2     },
+		{"winRate",           pm.WinRate,                  60    },
+		{"profitFactor",      pm.ProfitFactor,             4     },
+		{"avgWin",            pm.AvgWin,                   106.66},
+		{"avgLoss",           pm.AvgLoss,                  -40   },
+		{"largestWin",        pm.LargestWin,               200   },
+		{"largestLoss",       pm.LargestLoss,              -50   },
+		{"maxConsecWins",     float64(pm.MaxConsecWins),   2     },
+		{"maxConsecLosses",   float64(pm.MaxConsecLosses), 2     },
+		{"maxDrawdown",       pm.MaxDrawdown,              80    },
+		{"maxDrawdownDays",   float64(pm.MaxDrawdownDays), 6     },
+	}
+
+	for _, tt := range tests {
+		if !isNear(tt.value, tt.expected) {
+			t.Errorf("%v: expected %v but got %v", tt.name, tt.expected, tt.value)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestPerformanceMetricsPeriodPnl(t *testing.T) {
+	pm := NewPerformanceMetrics(metricsTrades)
+
+	if len(pm.YearlyPnl) != 1 || pm.YearlyPnl[0].NetProfit != 240 || pm.YearlyPnl[0].NumTrades != 5 {
+		t.Errorf("Unexpected yearly P&L: %+v", pm.YearlyPnl)
+	}
+
+	if len(pm.MonthlyPnl) != 2 || pm.MonthlyPnl[0].NetProfit != 220 || pm.MonthlyPnl[1].NetProfit != 20 || pm.MonthlyPnl[1].Month != 4 {
+		t.Errorf("Unexpected monthly P&L: %+v", pm.MonthlyPnl)
+	}
+
+	if pm.SharpeRatio <= 0 || pm.SortinoRatio <= 0 || pm.UlcerIndex <= 0 {
+		t.Errorf("Expected positive ratios: sharpe=%v sortino=%v ulcer=%v", pm.SharpeRatio, pm.SortinoRatio, pm.UlcerIndex)
+	}
+}
+
+//=============================================================================
+//--- 21 weekdays from March 4th to April 1st, plus the Sunday with a trade
+
+func TestCalcDailyPnl(t *testing.T) {
+	daily := calcDailyPnl(metricsTrades)
+
+	sum := 0.0
+	for _, pnl := range daily {
+		sum += pnl
+	}
+
+	if len(daily) != 22 || sum != 240 {
+		t.Errorf("Expected 22 days with a total of 240 but got %v days with %v", len(daily), sum)
+	}
+}
+
+//=============================================================================
+
+func TestPerformanceMetricsWithoutTrades(t *testing.T) {
+	pm := NewPerformanceMetrics(nil)
+
+	if pm.NumTrades != 0 || pm.YearlyPnl == nil || pm.MonthlyPnl == nil || pm.MaxDrawdown != 0 {
+		t.Errorf("Unexpected metrics without trades: %+v", pm)
+	}
+}
+
+//=============================================================================
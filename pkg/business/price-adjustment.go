@@ -0,0 +1,210 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"math"
+	"time"
+
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/data-collector/pkg/db"
+	"github.com/bit-fever/data-collector/pkg/ds"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+const (
+	AdjustmentNone  = "none"
+	AdjustmentSplit = "split"
+	AdjustmentAll   = "all"
+)
+
+//--- How far before an ex-date we look for the previous close
+const DividendLookback = 10 * 24 * time.Hour
+
+//=============================================================================
+//===
+//=== PriceAdjustment
+//===
+//=============================================================================
+
+type PriceAdjustment struct {
+	Mode     string
+	actions *[]db.CorporateAction
+}
+
+//-----------------------------------------------------------------------------
+
+type adjustmentFactor struct {
+	exDate      time.Time
+	priceFactor float64
+	volFactor   float64
+}
+
+//=============================================================================
+//--- Sets the corporate actions to adjust the instrument's prices back in time.
+//--- Nothing is done for unadjusted queries or when there are no actions
+
+func SetPriceAdjustment(tx *gorm.DB, c *auth.Context, config *DataConfig, diId uint, mode string) error {
+	if mode == "" || mode == AdjustmentNone {
+		return nil
+	}
+
+	if mode != AdjustmentSplit && mode != AdjustmentAll {
+		return req.NewBadRequestError("Invalid adjustment mode: %v", mode)
+	}
+
+	if config.VirtualInstrument || config.Synthetic != nil {
+		return req.NewBadRequestError("Adjustments are not allowed on virtual or synthetic instruments: %v", diId)
+	}
+
+	list, err := db.GetCorporateActionsByInstrumentId(tx, diId)
+	if err != nil {
+		c.Log.Error("SetPriceAdjustment: Could not retrieve corporate actions", "error", err.Error())
+		return err
+	}
+
+	if len(*list) > 0 {
+		config.Adjustment = &PriceAdjustment{
+			Mode   : mode,
+			actions: list,
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
+//--- Each bar is multiplied by the product of the factors of all actions that
+//--- happen after it, so that the last price remains unchanged
+
+func (pa *PriceAdjustment) AdjustBars(params *DataInstrumentDataParams, config *DataConfig, points []*ds.DataPoint) error {
+	factors, err := pa.calcFactors(params, config)
+	if err != nil || len(factors) == 0 {
+		return err
+	}
+
+	priceFactor := 1.0
+	volFactor   := 1.0
+	index       := len(factors) -1
+
+	for i := len(points) -1; i >= 0; i-- {
+		dp := points[i]
+
+		for index >= 0 && dp.Time.Before(factors[index].exDate) {
+			priceFactor *= factors[index].priceFactor
+			volFactor   *= factors[index].volFactor
+			index--
+		}
+
+		if priceFactor != 1 {
+			dp.Open  *= priceFactor
+			dp.High  *= priceFactor
+			dp.Low   *= priceFactor
+			dp.Close *= priceFactor
+		}
+
+		if volFactor != 1 {
+			dp.UpVolume   = int(math.Round(float64(dp.UpVolume)   * volFactor))
+			dp.DownVolume = int(math.Round(float64(dp.DownVolume) * volFactor))
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
+//===
+//=== Private methods
+//===
+//=============================================================================
+
+func (pa *PriceAdjustment) calcFactors(params *DataInstrumentDataParams, config *DataConfig) ([]*adjustmentFactor, error) {
+	var factors []*adjustmentFactor
+
+	for _, ca := range *pa.actions {
+		//--- Actions before the first bar don't change anything
+
+		if ca.ExDate.Before(params.From) {
+			continue
+		}
+
+		switch ca.Type {
+			case db.CATypeSplit:
+				factors = append(factors, &adjustmentFactor{
+					exDate     : ca.ExDate,
+					priceFactor: 1 / ca.Value,
+					volFactor  : ca.Value,
+				})
+
+			case db.CATypeDividend:
+				if pa.Mode != AdjustmentAll {
+					continue
+				}
+
+				prevClose, err := getPreviousClose(params, config, ca.ExDate)
+				if err != nil {
+					return nil, err
+				}
+
+				//--- Without a previous close (or with a wrong amount) the dividend is ignored
+
+				if prevClose > ca.Value {
+					factors = append(factors, &adjustmentFactor{
+						exDate     : ca.ExDate,
+						priceFactor: 1 - ca.Value / prevClose,
+						volFactor  : 1,
+					})
+				}
+		}
+	}
+
+	return factors, nil
+}
+
+//=============================================================================
+//--- Returns the last unadjusted close before the ex-date, or 0 if there is no data
+
+func getPreviousClose(params *DataInstrumentDataParams, config *DataConfig, exDate time.Time) (float64, error) {
+	dc := config.DataConfig
+	dc.Timeframe = "60m"
+
+	da  := ds.NewDataAggregator(nil, nil)
+	err := ds.GetDataPoints(exDate.Add(-DividendLookback), exDate.Add(-time.Second), &dc, params.Location, da)
+	if err != nil {
+		return 0, err
+	}
+
+	points := da.DataPoints()
+	if len(points) == 0 {
+		return 0, nil
+	}
+
+	return points[len(points) -1].Close, nil
+}
+
+//=============================================================================
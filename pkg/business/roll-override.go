@@ -0,0 +1,258 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/msg"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/data-collector/pkg/core/messaging/rollover"
+	"github.com/bit-fever/data-collector/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func GetRollOverridesByProductId(tx *gorm.DB, c *auth.Context, pId uint) (*[]db.RollOverrideFull, error) {
+	_, err := getDataProductAndCheckAccess(tx, c, pId, "GetRollOverridesByProductId")
+	if err != nil {
+		return nil, err
+	}
+
+	return db.GetRollOverridesByProductId(tx, pId)
+}
+
+//=============================================================================
+
+func AddRollOverride(tx *gorm.DB, c *auth.Context, pId uint, spec *RollOverrideSpec) (*db.RollOverride, error) {
+	c.Log.Info("AddRollOverride: Adding a new roll override", "dpId", pId, "diId", spec.DataInstrumentId)
+
+	err := checkRollOverrideSpec(tx, c, pId, spec, "AddRollOverride")
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkNoRollOverride(tx, spec.DataInstrumentId)
+	if err != nil {
+		return nil, err
+	}
+
+	ro := &db.RollOverride{
+		DataProductId   : pId,
+		DataInstrumentId: spec.DataInstrumentId,
+		RolloverDate    : spec.RolloverDate.UTC(),
+		Notes           : spec.Notes,
+	}
+
+	err = db.AddRollOverride(tx, ro)
+	if err != nil {
+		c.Log.Error("AddRollOverride: Could not add a new roll override", "error", err.Error())
+		return nil, err
+	}
+
+	c.Log.Info("AddRollOverride: Roll override added", "dpId", pId, "id", ro.Id)
+	return ro, nil
+}
+
+//=============================================================================
+
+func UpdateRollOverride(tx *gorm.DB, c *auth.Context, pId uint, id uint, spec *RollOverrideSpec) (*db.RollOverride, error) {
+	c.Log.Info("UpdateRollOverride: Updating a roll override", "dpId", pId, "id", id)
+
+	ro, err := getRollOverride(tx, c, pId, id, "UpdateRollOverride")
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkRollOverrideSpec(tx, c, pId, spec, "UpdateRollOverride")
+	if err != nil {
+		return nil, err
+	}
+
+	if spec.DataInstrumentId != ro.DataInstrumentId {
+		err = checkNoRollOverride(tx, spec.DataInstrumentId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ro.DataInstrumentId = spec.DataInstrumentId
+	ro.RolloverDate     = spec.RolloverDate.UTC()
+	ro.Notes            = spec.Notes
+
+	err = db.UpdateRollOverride(tx, ro)
+	if err != nil {
+		c.Log.Error("UpdateRollOverride: Could not update a roll override", "error", err.Error())
+		return nil, err
+	}
+
+	c.Log.Info("UpdateRollOverride: Roll override updated", "dpId", pId, "id", ro.Id)
+	return ro, nil
+}
+
+//=============================================================================
+
+func DeleteRollOverride(tx *gorm.DB, c *auth.Context, pId uint, id uint) (*db.RollOverride, error) {
+	c.Log.Info("DeleteRollOverride: Deleting a roll override", "dpId", pId, "id", id)
+
+	ro, err := getRollOverride(tx, c, pId, id, "DeleteRollOverride")
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.DeleteRollOverride(tx, id)
+	if err != nil {
+		c.Log.Error("DeleteRollOverride: Could not delete a roll override", "error", err.Error())
+		return nil, err
+	}
+
+	c.Log.Info("DeleteRollOverride: Roll override deleted", "dpId", pId, "id", id)
+	return ro, nil
+}
+
+//=============================================================================
+
+func UpdateRollSettings(tx *gorm.DB, c *auth.Context, pId uint, spec *RollSettingsSpec) (*db.DataProduct, error) {
+	c.Log.Info("UpdateRollSettings: Updating roll settings", "dpId", pId, "estimator", spec.Estimator, "timeframe", spec.Timeframe)
+
+	dp, err := getDataProductAndCheckAccess(tx, c, pId, "UpdateRollSettings")
+	if err != nil {
+		return nil, err
+	}
+
+	if spec.Estimator != db.DPRollEstimatorFirst && spec.Estimator != db.DPRollEstimatorMedian && spec.Estimator != db.DPRollEstimatorVWMean {
+		return nil, req.NewBadRequestError("Invalid roll estimator: %v", spec.Estimator)
+	}
+
+	if spec.Timeframe != "1m" && spec.Timeframe != "60m" {
+		return nil, req.NewBadRequestError("Invalid roll timeframe (must be 1m or 60m): %v", spec.Timeframe)
+	}
+
+	err = db.UpdateDataProductRollSettings(tx, pId, spec.Estimator, spec.Timeframe)
+	if err != nil {
+		c.Log.Error("UpdateRollSettings: Could not update roll settings", "error", err.Error())
+		return nil, err
+	}
+
+	dp.RollEstimator = spec.Estimator
+	dp.RollTimeframe = spec.Timeframe
+
+	c.Log.Info("UpdateRollSettings: Roll settings updated", "dpId", pId)
+	return dp, nil
+}
+
+//=============================================================================
+//--- Must be called after the transaction commits, otherwise the recalc could
+//--- read the old overrides
+
+func SendRollRecalcMessage(c *auth.Context, pId uint, trigger db.RHTrigger, force bool) error {
+	job := &rollover.RecalcJob{
+		DataProductId: pId,
+		ForceRecalc  : force,
+		Trigger      : trigger,
+		Username     : c.Session.Username,
+	}
+
+	err := msg.SendMessage(msg.ExCollector, msg.SourceRollRecalcJob, msg.TypeCreate, job)
+
+	if err != nil {
+		c.Log.Error("SendRollRecalcMessage: Could not publish the recalc message", "error", err.Error())
+	}
+
+	return err
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func getRollOverride(tx *gorm.DB, c *auth.Context, pId uint, id uint, function string) (*db.RollOverride, error) {
+	_, err := getDataProductAndCheckAccess(tx, c, pId, function)
+	if err != nil {
+		return nil, err
+	}
+
+	ro, err := db.GetRollOverrideById(tx, id)
+	if err != nil {
+		c.Log.Error(function +": Could not retrieve roll override", "error", err.Error())
+		return nil, err
+	}
+
+	if ro == nil || ro.DataProductId != pId {
+		c.Log.Error(function +": Roll override was not found", "dpId", pId, "id", id)
+		return nil, req.NewNotFoundError("Roll override was not found: %v", id)
+	}
+
+	return ro, nil
+}
+
+//=============================================================================
+//--- Only one roll override is allowed for each instrument
+
+func checkNoRollOverride(tx *gorm.DB, diId uint) error {
+	ro, err := db.GetRollOverrideByInstrumentId(tx, diId)
+	if err != nil {
+		return err
+	}
+
+	if ro != nil {
+		return req.NewBadRequestError("Data instrument has already a roll override: %v", diId)
+	}
+
+	return nil
+}
+
+//=============================================================================
+
+func checkRollOverrideSpec(tx *gorm.DB, c *auth.Context, pId uint, spec *RollOverrideSpec, function string) error {
+	_, err := getDataProductAndCheckAccess(tx, c, pId, function)
+	if err != nil {
+		return err
+	}
+
+	di, err := db.GetDataInstrumentById(tx, spec.DataInstrumentId)
+	if err != nil {
+		return err
+	}
+
+	if di == nil || di.DataProductId != pId {
+		c.Log.Error(function +": Data instrument not found in product", "dpId", pId, "diId", spec.DataInstrumentId)
+		return req.NewNotFoundError("Data instrument was not found in product: %v", spec.DataInstrumentId)
+	}
+
+	if di.Continuous || di.VirtualInstrument || di.ExpirationDate == nil {
+		return req.NewBadRequestError("Roll overrides can only be set on expiring instruments: %v", di.Symbol)
+	}
+
+	if spec.RolloverDate.After(*di.ExpirationDate) {
+		return req.NewBadRequestError("Rollover date cannot be after the expiration date: %v", di.Symbol)
+	}
+
+	return nil
+}
+
+//=============================================================================
@@ -0,0 +1,161 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/data-collector/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func GetRolloverHistory(tx *gorm.DB, c *auth.Context, id uint) (*[]db.RolloverHistory, error) {
+	_, err := getDataInstrumentAndCheckAccess(tx, c, id, "GetRolloverHistory")
+	if err != nil {
+		return nil, err
+	}
+
+	return db.GetRolloverHistoryByInstrumentId(tx, id)
+}
+
+//=============================================================================
+//--- When pinned, the rollover date and delta are never changed by a recalc.
+//--- When unpinned, the caller must send a forced recalc to compute them again
+
+func SetManualRollover(tx *gorm.DB, c *auth.Context, id uint, spec *ManualRolloverSpec) (*db.DataInstrument, error) {
+	c.Log.Info("SetManualRollover: Setting manual rollover", "id", id, "manual", spec.Manual)
+
+	di, err := getDataInstrumentAndCheckAccess(tx, c, id, "SetManualRollover")
+	if err != nil {
+		return nil, err
+	}
+
+	if di.Continuous || di.VirtualInstrument || di.ExpirationDate == nil {
+		return nil, req.NewBadRequestError("Manual rollovers can only be set on expiring instruments: %v", di.Symbol)
+	}
+
+	if !spec.Manual {
+		//--- Values are kept until the next recalc, but the unpin is recorded too
+
+		rh := &db.RolloverHistory{
+			DataProductId   : di.DataProductId,
+			DataInstrumentId: di.Id,
+			Trigger         : db.RHTriggerUnpin,
+			Username        : c.Session.Username,
+			OldDate         : di.RolloverDate,
+			OldDelta        : di.RolloverDelta,
+			OldStatus       : di.RolloverStatus,
+			NewDate         : di.RolloverDate,
+			NewDelta        : di.RolloverDelta,
+			NewStatus       : di.RolloverStatus,
+		}
+
+		di.RolloverManual = false
+		err = db.UpdateDataInstrument(tx, di)
+		if err == nil {
+			err = db.AddRolloverHistory(tx, rh)
+		}
+
+		if err != nil {
+			c.Log.Error("SetManualRollover: Could not remove the manual rollover", "error", err.Error())
+			return nil, err
+		}
+
+		c.Log.Info("SetManualRollover: Manual rollover removed", "id", id)
+		return di, nil
+	}
+
+	if spec.RolloverDate == nil {
+		return nil, req.NewBadRequestError("Rollover date is required for a manual rollover: %v", di.Symbol)
+	}
+
+	if spec.RolloverDate.After(*di.ExpirationDate) {
+		return nil, req.NewBadRequestError("Rollover date cannot be after the expiration date: %v", di.Symbol)
+	}
+
+	rollDate := spec.RolloverDate.UTC()
+
+	rh := &db.RolloverHistory{
+		DataProductId   : di.DataProductId,
+		DataInstrumentId: di.Id,
+		Trigger         : db.RHTriggerManual,
+		Username        : c.Session.Username,
+		OldDate         : di.RolloverDate,
+		OldDelta        : di.RolloverDelta,
+		OldStatus       : di.RolloverStatus,
+		NewDate         : &rollDate,
+		NewDelta        : spec.RolloverDelta,
+		NewStatus       : db.DIRollStatusReady,
+	}
+
+	di.RolloverDate       = &rollDate
+	di.RolloverDelta      = spec.RolloverDelta
+	di.RolloverDispersion = 0
+	di.RolloverStatus     = db.DIRollStatusReady
+	di.RolloverManual     = true
+
+	err = db.UpdateDataInstrument(tx, di)
+	if err == nil {
+		err = db.AddRolloverHistory(tx, rh)
+	}
+
+	if err != nil {
+		c.Log.Error("SetManualRollover: Could not pin the rollover", "error", err.Error())
+		return nil, err
+	}
+
+	c.Log.Info("SetManualRollover: Manual rollover set", "id", id, "date", rollDate, "delta", spec.RolloverDelta)
+	return di, nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func getDataInstrumentAndCheckAccess(tx *gorm.DB, c *auth.Context, id uint, function string) (*db.DataInstrument, error) {
+	di, err := db.GetDataInstrumentById(tx, id)
+	if err != nil {
+		c.Log.Error(function +": Could not retrieve data instrument", "error", err.Error())
+		return nil, err
+	}
+
+	if di == nil {
+		c.Log.Error(function +": Data instrument was not found", "id", id)
+		return nil, req.NewNotFoundError("Data instrument was not found: %v", id)
+	}
+
+	_, err = getDataProductAndCheckAccess(tx, c, di.DataProductId, function)
+	if err != nil {
+		return nil, err
+	}
+
+	return di, nil
+}
+
+//=============================================================================
@@ -0,0 +1,394 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/data-collector/pkg/core"
+	"github.com/bit-fever/data-collector/pkg/db"
+	"github.com/bit-fever/data-collector/pkg/ds"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+const (
+	MaxTradingDaysInMonth = 23
+
+	//--- Turn of month is the last trading day plus the first 3 of the next month
+	TurnOfMonthDaysBefore = 1
+	TurnOfMonthDaysAfter  = 3
+)
+
+//=============================================================================
+
+type SeasonalitySpec struct {
+	Excludes string
+	Years    string
+}
+
+//=============================================================================
+
+type SeasonalityResponse struct {
+	BiasAnalysis       *db.BiasAnalysis   `json:"biasAnalysis"`
+	Years               []int             `json:"years"`
+	Days                int               `json:"days"`
+	ByDayOfYear         []*SeasonalBucket `json:"byDayOfYear"`
+	ByTradingDay        []*SeasonalBucket `json:"byTradingDay"`
+	ByTradingDayFromEnd []*SeasonalBucket `json:"byTradingDayFromEnd"`
+	ByWeekOfMonth       []*SeasonalBucket `json:"byWeekOfMonth"`
+	ByMonth             []*SeasonalBucket `json:"byMonth"`
+	TurnOfMonth        *TurnOfMonthStats  `json:"turnOfMonth"`
+
+	config             *DataConfig
+	excludedSet        *ExcludedSet
+	years               map[int]bool
+}
+
+//=============================================================================
+//--- Returns are daily close to close percentages. Cumulative is the seasonal
+//--- curve, that is the sum of the average returns up to this bucket
+
+type SeasonalBucket struct {
+	Count        int     `json:"count"`
+	AvgReturn    float64 `json:"avgReturn"`
+	MedianReturn float64 `json:"medianReturn"`
+	WinRatio     float64 `json:"winRatio"`
+	Cumulative   float64 `json:"cumulative"`
+
+	returns []float64
+}
+
+//-----------------------------------------------------------------------------
+
+func (sb *SeasonalBucket) add(ret float64) {
+	sb.returns = append(sb.returns, ret)
+}
+
+//-----------------------------------------------------------------------------
+
+func (sb *SeasonalBucket) calc(prevCumulative float64) {
+	sb.Count = len(sb.returns)
+
+	if sb.Count > 0 {
+		sum  := 0.0
+		wins := 0
+		for _, r := range sb.returns {
+			sum += r
+			if r > 0 {
+				wins++
+			}
+		}
+
+		sb.AvgReturn    = sum / float64(sb.Count)
+		sb.MedianReturn = core.Median(sb.returns)
+		sb.WinRatio     = float64(wins) * 100 / float64(sb.Count)
+	}
+
+	sb.Cumulative = prevCumulative + sb.AvgReturn
+}
+
+//=============================================================================
+
+type TurnOfMonthStats struct {
+	Inside  *SeasonalBucket `json:"inside"`
+	Outside *SeasonalBucket `json:"outside"`
+}
+
+//=============================================================================
+//--- Trading days are 0 when unknown, in the incomplete months at the borders
+
+type seasonalDay struct {
+	date  time.Time
+	ret   float64
+	tdBeg int
+	tdEnd int
+}
+
+//=============================================================================
+
+func GetSeasonalityInfo(tx *gorm.DB, c *auth.Context, id uint, spec *SeasonalitySpec) (*SeasonalityResponse, error) {
+	c.Log.Info("GetSeasonalityInfo: Getting bias analysis", "id", id)
+
+	ba, err := getBiasAnalysisAndCheckAccess(tx, c, id, "GetSeasonalityInfo")
+	if err != nil {
+		return nil, err
+	}
+
+	es, err := NewExcludedSet(core.DecodeExcludes(spec.Excludes))
+	if err != nil {
+		return nil, req.NewBadRequestError("Bad excludes: %v (%v)", spec.Excludes, err.Error())
+	}
+
+	years, err := parseYears(spec.Years)
+	if err != nil {
+		return nil, req.NewBadRequestError("Bad years: %v (%v)", spec.Years, err.Error())
+	}
+
+	config, err := CreateDataConfig(tx, ba.DataInstrumentId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SeasonalityResponse{
+		BiasAnalysis: ba,
+		Years       : []int{},
+		config      : config,
+		excludedSet : es,
+		years       : years,
+	}, nil
+}
+
+//=============================================================================
+
+func GetSeasonalityData(c *auth.Context, id uint, sr *SeasonalityResponse) error {
+	sr.config.DataConfig.Timeframe = "1440m"
+
+	loc,_:= time.LoadLocation(sr.config.Timezone)
+	da   := ds.NewDataAggregator(nil, loc)
+
+	dataPoints, err := getDataPoints(newDefaultDataParams(loc, da), sr.config)
+	if err != nil {
+		return err
+	}
+
+	days := buildSeasonalDays(dataPoints)
+	sr.initBuckets()
+
+	yearSet := map[int]bool{}
+
+	for _, sd := range days {
+		y,m,_ := sd.date.Date()
+
+		if sr.years != nil && !sr.years[y] {
+			continue
+		}
+
+		if sr.excludedSet.ShouldBeExcluded(int16(m), int16(y)) {
+			continue
+		}
+
+		sr.add(sd)
+		if !yearSet[y] {
+			yearSet[y] = true
+			sr.Years   = append(sr.Years, y)
+		}
+	}
+
+	sr.calc()
+
+	c.Log.Info("GetSeasonalityData: Seasonality calculated", "id", id, "days", sr.Days, "years", len(sr.Years))
+	return nil
+}
+
+//=============================================================================
+//===
+//=== Private methods
+//===
+//=============================================================================
+
+func (sr *SeasonalityResponse) initBuckets() {
+	sr.ByDayOfYear         = newSeasonalBuckets(366)
+	sr.ByTradingDay        = newSeasonalBuckets(MaxTradingDaysInMonth)
+	sr.ByTradingDayFromEnd = newSeasonalBuckets(MaxTradingDaysInMonth)
+	sr.ByWeekOfMonth       = newSeasonalBuckets(5)
+	sr.ByMonth             = newSeasonalBuckets(12)
+	sr.TurnOfMonth         = &TurnOfMonthStats{
+		Inside : &SeasonalBucket{},
+		Outside: &SeasonalBucket{},
+	}
+}
+
+//=============================================================================
+
+func (sr *SeasonalityResponse) add(sd *seasonalDay) {
+	sr.Days++
+	sr.ByDayOfYear[dayOfYearIndex(sd.date)].add(sd.ret)
+	sr.ByWeekOfMonth[(sd.date.Day() -1) / 7].add(sd.ret)
+	sr.ByMonth[sd.date.Month() -1].add(sd.ret)
+
+	if sd.tdBeg == 0 {
+		return
+	}
+
+	if sd.tdBeg <= MaxTradingDaysInMonth {
+		sr.ByTradingDay[sd.tdBeg -1].add(sd.ret)
+	}
+
+	if sd.tdEnd <= MaxTradingDaysInMonth {
+		sr.ByTradingDayFromEnd[sd.tdEnd -1].add(sd.ret)
+	}
+
+	if sd.tdEnd <= TurnOfMonthDaysBefore || sd.tdBeg <= TurnOfMonthDaysAfter {
+		sr.TurnOfMonth.Inside.add(sd.ret)
+	} else {
+		sr.TurnOfMonth.Outside.add(sd.ret)
+	}
+}
+
+//=============================================================================
+
+func (sr *SeasonalityResponse) calc() {
+	for _, list := range [][]*SeasonalBucket{ sr.ByDayOfYear, sr.ByTradingDay, sr.ByTradingDayFromEnd, sr.ByWeekOfMonth, sr.ByMonth } {
+		cumulative := 0.0
+		for _, sb := range list {
+			sb.calc(cumulative)
+			cumulative = sb.Cumulative
+		}
+	}
+
+	sr.TurnOfMonth.Inside .calc(0)
+	sr.TurnOfMonth.Outside.calc(0)
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func newSeasonalBuckets(size int) []*SeasonalBucket {
+	list := make([]*SeasonalBucket, size)
+	for i := range list {
+		list[i] = &SeasonalBucket{}
+	}
+
+	return list
+}
+
+//=============================================================================
+//--- Days are placed as in a leap year, so that a date always gets the same
+//--- bucket and Feb 29 has its own one
+
+func dayOfYearIndex(date time.Time) int {
+	return time.Date(2000, date.Month(), date.Day(), 0, 0, 0, 0, time.UTC).YearDay() -1
+}
+
+//=============================================================================
+//--- Daily bars' times mark the end of the day (midnight of the next one).
+//--- Trading days are counted from the start and from the end of each month.
+//--- The last month could still be open and the first one is complete only if
+//--- the data starts in the previous month, so they are not counted otherwise
+
+func buildSeasonalDays(dataPoints []*ds.DataPoint) []*seasonalDay {
+	var days []*seasonalDay
+
+	monthStart := 0
+	firstEnd   := 0
+
+	for i := 1; i < len(dataPoints); i++ {
+		prev := dataPoints[i-1]
+		curr := dataPoints[i]
+
+		if prev.Close == 0 {
+			continue
+		}
+
+		sd := &seasonalDay{
+			date: curr.Time.Add(-time.Minute),
+			ret : (curr.Close - prev.Close) * 100 / prev.Close,
+		}
+
+		if len(days) > 0 && days[len(days)-1].date.Month() != sd.date.Month() {
+			setTradingDaysFromEnd(days[monthStart:])
+			monthStart = len(days)
+
+			if firstEnd == 0 {
+				firstEnd = monthStart
+			}
+		}
+
+		sd.tdBeg = len(days) - monthStart +1
+		days = append(days, sd)
+	}
+
+	clearTradingDays(days[monthStart:])
+
+	if firstEnd > 0 && isSameMonth(days[0].date, dataPoints[0].Time.Add(-time.Minute)) {
+		clearTradingDays(days[:firstEnd])
+	}
+
+	return days
+}
+
+//=============================================================================
+
+func setTradingDaysFromEnd(month []*seasonalDay) {
+	for i, sd := range month {
+		sd.tdEnd = len(month) - i
+	}
+}
+
+//=============================================================================
+
+func clearTradingDays(month []*seasonalDay) {
+	for _, sd := range month {
+		sd.tdBeg = 0
+		sd.tdEnd = 0
+	}
+}
+
+//=============================================================================
+
+func isSameMonth(t1, t2 time.Time) bool {
+	y1, m1, _ := t1.Date()
+	y2, m2, _ := t2.Date()
+
+	return y1 == y2 && m1 == m2
+}
+
+//=============================================================================
+//--- Years are a comma separated list. An empty value means all years
+
+func parseYears(value string) (map[int]bool, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	years := map[int]bool{}
+
+	for _, item := range strings.Split(value, ",") {
+		y, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil {
+			return nil, err
+		}
+
+		if y < 1900 || y > 3000 {
+			return nil, errors.New("year out of range: "+ item)
+		}
+
+		years[y] = true
+	}
+
+	return years, nil
+}
+
+//=============================================================================
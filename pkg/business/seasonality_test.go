@@ -0,0 +1,57 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"testing"
+	"time"
+)
+
+//=============================================================================
+
+func TestDayOfYearIndex(t *testing.T) {
+	tests := []struct {
+		name     string
+		date     time.Time
+		expected int
+	}{
+		{"first day",           time.Date(2023, 1,  1, 0,0,0,0, time.UTC), 0   },
+		{"feb 28, normal year", time.Date(2023, 2, 28, 0,0,0,0, time.UTC), 58  },
+		{"feb 28, leap year",   time.Date(2024, 2, 28, 0,0,0,0, time.UTC), 58  },
+		{"feb 29",              time.Date(2024, 2, 29, 0,0,0,0, time.UTC), 59  },
+		{"mar 1, normal year",  time.Date(2023, 3,  1, 0,0,0,0, time.UTC), 60  },
+		{"mar 1, leap year",    time.Date(2024, 3,  1, 0,0,0,0, time.UTC), 60  },
+		{"last day",            time.Date(2023,12, 31, 0,0,0,0, time.UTC), 365 },
+	}
+
+	for _, tt := range tests {
+		index := dayOfYearIndex(tt.date)
+		if index != tt.expected {
+			t.Errorf("%v: expected %v but got %v", tt.name, tt.expected, index)
+		}
+	}
+}
+
+//=============================================================================
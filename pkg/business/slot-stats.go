@@ -0,0 +1,132 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"github.com/bit-fever/data-collector/pkg/core"
+)
+
+//=============================================================================
+
+const (
+	BootstrapSamples = 1000
+
+	//--- The seed is fixed so that the same data always gives the same p-value
+	BootstrapSeed = 1
+)
+
+//=============================================================================
+//--- Statistics of the deltas of a slot. The t-statistic and the p-value test
+//--- the hypothesis that the mean delta is zero
+
+type SlotStats struct {
+	Count        int     `json:"count"`
+	Mean         float64 `json:"mean"`
+	Median       float64 `json:"median"`
+	StdDev       float64 `json:"stdDev"`
+	PositivePerc float64 `json:"positivePerc"`
+	TStat        float64 `json:"tStat"`
+	PValue       float64 `json:"pValue"`
+}
+
+//=============================================================================
+
+func NewSlotStats(values []float64) *SlotStats {
+	ss := &SlotStats{
+		Count : len(values),
+		PValue: 1,
+	}
+
+	if ss.Count == 0 {
+		return ss
+	}
+
+	sum      := 0.0
+	positive := 0
+	for _, v := range values {
+		sum += v
+		if v > 0 {
+			positive++
+		}
+	}
+
+	ss.Mean         = sum / float64(ss.Count)
+	ss.Median       = core.Median(values)
+	ss.PositivePerc = float64(positive) * 100 / float64(ss.Count)
+
+	if ss.Count < 2 {
+		return ss
+	}
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - ss.Mean) * (v - ss.Mean)
+	}
+
+	ss.StdDev = math.Sqrt(variance / float64(ss.Count -1))
+
+	if ss.StdDev > 0 {
+		ss.TStat  = ss.Mean / (ss.StdDev / math.Sqrt(float64(ss.Count)))
+		ss.PValue = calcBootstrapPValue(values, ss.Mean)
+	}
+
+	return ss
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+//--- Two-sided bootstrap test: values are shifted to have zero mean (the null
+//--- hypothesis) and resampled. The p-value is the fraction of resampled means
+//--- that are at least as far from zero as the observed one
+
+func calcBootstrapPValue(values []float64, mean float64) float64 {
+	rnd   := rand.New(rand.NewPCG(BootstrapSeed, BootstrapSeed))
+	n     := len(values)
+	limit := math.Abs(mean)
+	count := 0
+
+	for i := 0; i < BootstrapSamples; i++ {
+		sum := 0.0
+		for j := 0; j < n; j++ {
+			sum += values[rnd.IntN(n)]
+		}
+
+		if math.Abs(sum / float64(n) - mean) >= limit {
+			count++
+		}
+	}
+
+	//--- Add one to avoid p-values equal to zero
+
+	return float64(count +1) / float64(BootstrapSamples +1)
+}
+
+//=============================================================================
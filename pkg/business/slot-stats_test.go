@@ -0,0 +1,94 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"math"
+	"testing"
+)
+
+//=============================================================================
+
+func TestNewSlotStats(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		mean     float64
+		median   float64
+		stdDev   float64
+		positive float64
+		tStat    float64
+	}{
+		{"No values",       nil,                       0,   0,   0,      0,   0    },
+		{"Single value",    []float64{ 5 },            5,   5,   0,      100, 0    },
+		{"Constant values", []float64{ 2, 2, 2 },      2,   2,   0,      100, 0    },
+		{"Zero mean",       []float64{ -1, 1, -1, 1 }, 0,   0,   1.1547, 50,  0    },
+		{"Positive mean",   []float64{ 4, 1, 3, 2 },   2.5, 2.5, 1.2910, 100, 3.873},
+	}
+
+	for _, tt := range tests {
+		ss := NewSlotStats(tt.values)
+
+		if ss.Count != len(tt.values) || !isNear(ss.Mean, tt.mean) || !isNear(ss.Median, tt.median) ||
+			!isNear(ss.StdDev, tt.stdDev) || !isNear(ss.PositivePerc, tt.positive) || !isNear(ss.TStat, tt.tStat) {
+			t.Errorf("%v: unexpected stats %+v", tt.name, ss)
+		}
+	}
+}
+
+//=============================================================================
+//--- Without a deviation there is nothing to test, while a clear positive
+//--- mean must be significant. The seed is fixed, so p-values are repeatable
+
+func TestSlotStatsPValue(t *testing.T) {
+	if ss := NewSlotStats([]float64{ 2, 2, 2 }); ss.PValue != 1 {
+		t.Errorf("Constant values: expected p-value 1 but got %v", ss.PValue)
+	}
+
+	if ss := NewSlotStats([]float64{ -1, 1, -1, 1 }); ss.PValue != 1 {
+		t.Errorf("Zero mean: expected p-value 1 but got %v", ss.PValue)
+	}
+
+	values := []float64{ 3, 5, 2, 4, 6, 3, 5, 4, 2, 6, 1, 4 }
+
+	ss1 := NewSlotStats(values)
+	ss2 := NewSlotStats(values)
+
+	if ss1.PValue >= 0.05 {
+		t.Errorf("Positive mean: expected a significant p-value but got %v", ss1.PValue)
+	}
+
+	if ss1.PValue != ss2.PValue {
+		t.Errorf("P-values must be repeatable: %v != %v", ss1.PValue, ss2.PValue)
+	}
+}
+
+//=============================================================================
+
+func isNear(value, expected float64) bool {
+	return math.Abs(value - expected) < 0.001
+}
+
+//=============================================================================
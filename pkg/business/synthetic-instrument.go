@@ -0,0 +1,230 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/data-collector/pkg/core/synthetic"
+	"github.com/bit-fever/data-collector/pkg/db"
+	"github.com/bit-fever/data-collector/pkg/ds"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func AddSyntheticInstrument(tx *gorm.DB, c *auth.Context, pId uint, spec *SyntheticInstrumentSpec) (*db.DataInstrument, error) {
+	c.Log.Info("AddSyntheticInstrument: Adding a new synthetic instrument", "dpId", pId, "symbol", spec.Symbol, "expression", spec.Expression)
+
+	dp, err := getDataProductAndCheckAccess(tx, c, pId, "AddSyntheticInstrument")
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err := synthetic.Parse(spec.Expression)
+	if err != nil {
+		return nil, req.NewBadRequestError("Invalid expression: %v", err.Error())
+	}
+
+	_, err = resolveSyntheticLegs(tx, dp.Username, expr)
+	if err != nil {
+		return nil, err
+	}
+
+	other, err := db.GetDataInstrumentBySymbol(tx, pId, spec.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if other != nil {
+		return nil, req.NewBadRequestError("An instrument with the same symbol already exists: %v", spec.Symbol)
+	}
+
+	name := spec.Name
+	if name == "" {
+		name = spec.Expression
+	}
+
+	di := &db.DataInstrument{
+		DataProductId: pId,
+		Symbol       : spec.Symbol,
+		Name         : name,
+		Continuous   : true,
+		Synthetic    : true,
+		Expression   : spec.Expression,
+	}
+
+	err = db.AddDataInstrument(tx, di)
+	if err != nil {
+		c.Log.Error("AddSyntheticInstrument: Could not add a new synthetic instrument", "error", err.Error())
+		return nil, err
+	}
+
+	c.Log.Info("AddSyntheticInstrument: Synthetic instrument added", "dpId", pId, "id", di.Id)
+	return di, nil
+}
+
+//=============================================================================
+
+func DeleteSyntheticInstrument(tx *gorm.DB, c *auth.Context, pId uint, id uint) (*db.DataInstrument, error) {
+	c.Log.Info("DeleteSyntheticInstrument: Deleting a synthetic instrument", "dpId", pId, "id", id)
+
+	_, err := getDataProductAndCheckAccess(tx, c, pId, "DeleteSyntheticInstrument")
+	if err != nil {
+		return nil, err
+	}
+
+	di, err := db.GetDataInstrumentById(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if di == nil || di.DataProductId != pId || !di.Synthetic {
+		c.Log.Error("DeleteSyntheticInstrument: Synthetic instrument was not found", "dpId", pId, "id", id)
+		return nil, req.NewNotFoundError("Synthetic instrument was not found: %v", id)
+	}
+
+	analyses, err := db.GetBiasAnalysesByInstrumentId(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(*analyses) != 0 {
+		return nil, req.NewBadRequestError("Synthetic instrument is used by %v bias analyses: %v", len(*analyses), di.Symbol)
+	}
+
+	err = db.DeleteDataInstrument(tx, id)
+	if err != nil {
+		c.Log.Error("DeleteSyntheticInstrument: Could not delete a synthetic instrument", "error", err.Error())
+		return nil, err
+	}
+
+	c.Log.Info("DeleteSyntheticInstrument: Synthetic instrument deleted", "dpId", pId, "id", id)
+	return di, nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func createSyntheticConfig(tx *gorm.DB, i *db.DataInstrument, p *db.DataProduct) (*DataConfig, error) {
+	expr, err := synthetic.Parse(i.Expression)
+	if err != nil {
+		return nil, req.NewBadRequestError("Invalid expression for %v: %v", i.Symbol, err.Error())
+	}
+
+	legIds, err := resolveSyntheticLegs(tx, p.Username, expr)
+	if err != nil {
+		return nil, err
+	}
+
+	config := createConfig(i, p, nil)
+	config.Synthetic = expr
+	config.Legs      = map[string]*DataConfig{}
+
+	for symbol, legId := range legIds {
+		config.Legs[symbol], err = CreateDataConfig(tx, legId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return config, nil
+}
+
+//=============================================================================
+//--- A symbol can be a data instrument of any user's product. If not found, the
+//--- default virtual instrument is tried (i.e. ES -> #ES)
+
+func resolveSyntheticLegs(tx *gorm.DB, username string, expr *synthetic.Expression) (map[string]uint, error) {
+	legIds := map[string]uint{}
+
+	for _, symbol := range expr.Symbols() {
+		list, err := db.GetUserDataInstrumentsBySymbol(tx, username, symbol)
+		if err == nil && len(*list) == 0 {
+			list, err = db.GetUserDataInstrumentsBySymbol(tx, username, "#"+ symbol)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(*list) == 0 {
+			return nil, req.NewNotFoundError("Instrument of synthetic expression was not found: %v", symbol)
+		}
+
+		if len(*list) > 1 {
+			return nil, req.NewBadRequestError("Instrument of synthetic expression is ambiguous: %v", symbol)
+		}
+
+		leg := (*list)[0]
+		if leg.Synthetic {
+			return nil, req.NewBadRequestError("A synthetic expression cannot reference a synthetic instrument: %v", symbol)
+		}
+
+		legIds[symbol] = leg.Id
+	}
+
+	return legIds, nil
+}
+
+//=============================================================================
+//--- Each leg is queried with its own aggregator, using the timeframe of the
+//--- synthetic instrument
+
+func getSyntheticDataPoints(params *DataInstrumentDataParams, config *DataConfig) ([]*ds.DataPoint, error) {
+	legs := map[string][]*ds.DataPoint{}
+
+	for symbol, legConfig := range config.Legs {
+		legConfig.DataConfig.Timeframe = config.DataConfig.Timeframe
+
+		legParams := &DataInstrumentDataParams{
+			Location  : params.Location,
+			From      : params.From,
+			To        : params.To,
+			Reduction : params.Reduction,
+			Aggregator: params.Aggregator.NewEmptyCopy(),
+		}
+
+		points, err := getDataPoints(legParams, legConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		//--- A virtual leg without data
+
+		if points == nil {
+			return nil, nil
+		}
+
+		legs[symbol] = points
+	}
+
+	return synthetic.Combine(config.Synthetic, legs), nil
+}
+
+//=============================================================================
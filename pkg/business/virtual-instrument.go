@@ -0,0 +1,159 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"strings"
+
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/data-collector/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func AddVirtualInstrument(tx *gorm.DB, c *auth.Context, pId uint, spec *VirtualInstrumentSpec) (*db.DataInstrument, error) {
+	c.Log.Info("AddVirtualInstrument: Adding a new virtual instrument", "dpId", pId, "symbol", spec.Symbol)
+
+	dp, err := getDataProductAndCheckAccess(tx, c, pId, "AddVirtualInstrument")
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkVirtualInstrumentSpec(dp, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	symbol := spec.Symbol
+	if !strings.HasPrefix(symbol, "#") {
+		symbol = "#"+ symbol
+	}
+
+	other, err := db.GetDataInstrumentBySymbol(tx, pId, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if other != nil {
+		return nil, req.NewBadRequestError("An instrument with the same symbol already exists: %v", symbol)
+	}
+
+	name := spec.Name
+	if name == "" {
+		name = dp.Symbol +" ["+ spec.Months +", "+ string(spec.RollTrigger) +"]"
+	}
+
+	di := &db.DataInstrument{
+		DataProductId     : pId,
+		Symbol            : symbol,
+		Name              : name,
+		Continuous        : true,
+		VirtualInstrument : true,
+		VirtualMonths     : spec.Months,
+		VirtualRollTrigger: spec.RollTrigger,
+		RolloverStatus    : db.DIRollStatusWaiting,
+	}
+
+	err = db.AddDataInstrument(tx, di)
+	if err != nil {
+		c.Log.Error("AddVirtualInstrument: Could not add a new virtual instrument", "error", err.Error())
+		return nil, err
+	}
+
+	c.Log.Info("AddVirtualInstrument: Virtual instrument added", "dpId", pId, "id", di.Id)
+	return di, nil
+}
+
+//=============================================================================
+
+func DeleteVirtualInstrument(tx *gorm.DB, c *auth.Context, pId uint, id uint) (*db.DataInstrument, error) {
+	c.Log.Info("DeleteVirtualInstrument: Deleting a virtual instrument", "dpId", pId, "id", id)
+
+	_, err := getDataProductAndCheckAccess(tx, c, pId, "DeleteVirtualInstrument")
+	if err != nil {
+		return nil, err
+	}
+
+	di, err := db.GetDataInstrumentById(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if di == nil || di.DataProductId != pId || !di.VirtualInstrument {
+		c.Log.Error("DeleteVirtualInstrument: Virtual instrument was not found", "dpId", pId, "id", id)
+		return nil, req.NewNotFoundError("Virtual instrument was not found: %v", id)
+	}
+
+	if di.VirtualMonths == "" {
+		return nil, req.NewBadRequestError("The default virtual instrument cannot be deleted: %v", di.Symbol)
+	}
+
+	analyses, err := db.GetBiasAnalysesByInstrumentId(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(*analyses) != 0 {
+		return nil, req.NewBadRequestError("Virtual instrument is used by %v bias analyses: %v", len(*analyses), di.Symbol)
+	}
+
+	err = db.DeleteVirtualRolloversByVirtualId(tx, id)
+	if err == nil {
+		err = db.DeleteDataInstrument(tx, id)
+	}
+
+	if err != nil {
+		c.Log.Error("DeleteVirtualInstrument: Could not delete a virtual instrument", "error", err.Error())
+		return nil, err
+	}
+
+	c.Log.Info("DeleteVirtualInstrument: Virtual instrument deleted", "dpId", pId, "id", id)
+	return di, nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func checkVirtualInstrumentSpec(dp *db.DataProduct, spec *VirtualInstrumentSpec) error {
+	for _, m := range spec.Months {
+		if !strings.ContainsRune(dp.Months, m) {
+			return req.NewBadRequestError("Month '%c' is not part of the product's months: %v", m, dp.Months)
+		}
+	}
+
+	if spec.RollTrigger != db.DPRollTriggerSD4 && spec.RollTrigger != db.DPRollTriggerSD6 &&
+		spec.RollTrigger != db.DPRollTriggerSD30 && spec.RollTrigger != db.DPRollTriggerVolume {
+		return req.NewBadRequestError("Invalid roll trigger: %v", spec.RollTrigger)
+	}
+
+	return nil
+}
+
+//=============================================================================
@@ -0,0 +1,246 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package indicator
+
+import (
+	"math"
+	"time"
+
+	"github.com/bit-fever/data-collector/pkg/ds"
+)
+
+//=============================================================================
+//=== All functions return a slice with the same length of the input. Values
+//=== that cannot be computed yet (i.e. during the warm-up) are set to NaN
+//=============================================================================
+
+func Closes(points []*ds.DataPoint) []float64 {
+	res := make([]float64, len(points))
+
+	for i, dp := range points {
+		res[i] = dp.Close
+	}
+
+	return res
+}
+
+//=============================================================================
+
+func SMA(values []float64, period int) []float64 {
+	res := newSeries(len(values))
+	sum := 0.0
+
+	for i, v := range values {
+		sum += v
+		if i >= period {
+			sum -= values[i-period]
+		}
+
+		if i >= period -1 {
+			res[i] = sum / float64(period)
+		}
+	}
+
+	return res
+}
+
+//=============================================================================
+//--- The first value is the SMA of the first 'period' values
+
+func EMA(values []float64, period int) []float64 {
+	res := newSeries(len(values))
+	if len(values) < period {
+		return res
+	}
+
+	alpha := 2 / float64(period +1)
+	ema   := 0.0
+
+	for i := 0; i < period; i++ {
+		ema += values[i]
+	}
+
+	ema /= float64(period)
+	res[period -1] = ema
+
+	for i := period; i < len(values); i++ {
+		ema    = alpha * values[i] + (1 - alpha) * ema
+		res[i] = ema
+	}
+
+	return res
+}
+
+//=============================================================================
+//--- Average true range using Wilder's smoothing
+
+func ATR(points []*ds.DataPoint, period int) []float64 {
+	tr := make([]float64, len(points))
+
+	for i, dp := range points {
+		tr[i] = dp.High - dp.Low
+		if i > 0 {
+			prevClose := points[i-1].Close
+			tr[i] = math.Max(tr[i], math.Abs(dp.High - prevClose))
+			tr[i] = math.Max(tr[i], math.Abs(dp.Low  - prevClose))
+		}
+	}
+
+	return wilder(tr, period, 0)
+}
+
+//=============================================================================
+//--- Relative strength index using Wilder's smoothing
+
+func RSI(values []float64, period int) []float64 {
+	res := newSeries(len(values))
+	if len(values) <= period {
+		return res
+	}
+
+	gains  := make([]float64, len(values))
+	losses := make([]float64, len(values))
+
+	for i := 1; i < len(values); i++ {
+		delta := values[i] - values[i-1]
+		if delta > 0 {
+			gains[i] = delta
+		} else {
+			losses[i] = -delta
+		}
+	}
+
+	avgGain := wilder(gains,  period, 1)
+	avgLoss := wilder(losses, period, 1)
+
+	for i := period; i < len(values); i++ {
+		if avgLoss[i] == 0 {
+			res[i] = 100
+		} else {
+			res[i] = 100 - 100 / (1 + avgGain[i] / avgLoss[i])
+		}
+	}
+
+	return res
+}
+
+//=============================================================================
+//--- Returns the middle, upper and lower bands
+
+func Bollinger(values []float64, period int, stdDevs float64) ([]float64, []float64, []float64) {
+	middle := SMA(values, period)
+	upper  := newSeries(len(values))
+	lower  := newSeries(len(values))
+
+	for i := period -1; i < len(values); i++ {
+		variance := 0.0
+		for j := i - period +1; j <= i; j++ {
+			diff := values[j] - middle[i]
+			variance += diff * diff
+		}
+
+		dev := stdDevs * math.Sqrt(variance / float64(period))
+		upper[i] = middle[i] + dev
+		lower[i] = middle[i] - dev
+	}
+
+	return middle, upper, lower
+}
+
+//=============================================================================
+//--- Volume weighted average price on the typical price, restarting at each
+//--- session. Sessions are calendar days in the given location. As a bar's time
+//--- marks its end, a bar at midnight belongs to the previous day
+
+func VWAP(points []*ds.DataPoint, loc *time.Location) []float64 {
+	res := newSeries(len(points))
+
+	sumPV   := 0.0
+	sumV    := 0.0
+	currDay := ""
+
+	for i, dp := range points {
+		day := dp.Time.Add(-time.Nanosecond).In(loc).Format(time.DateOnly)
+		if day != currDay {
+			currDay = day
+			sumPV   = 0
+			sumV    = 0
+		}
+
+		volume := float64(dp.UpVolume + dp.DownVolume)
+		sumPV += volume * (dp.High + dp.Low + dp.Close) / 3
+		sumV  += volume
+
+		if sumV > 0 {
+			res[i] = sumPV / sumV
+		}
+	}
+
+	return res
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func newSeries(size int) []float64 {
+	res := make([]float64, size)
+	for i := range res {
+		res[i] = math.NaN()
+	}
+
+	return res
+}
+
+//=============================================================================
+//--- Wilder's smoothing, starting with the mean of 'period' values from 'start'
+
+func wilder(values []float64, period int, start int) []float64 {
+	res   := newSeries(len(values))
+	first := start + period -1
+
+	if len(values) <= first {
+		return res
+	}
+
+	avg := 0.0
+	for i := start; i <= first; i++ {
+		avg += values[i]
+	}
+
+	avg /= float64(period)
+	res[first] = avg
+
+	for i := first +1; i < len(values); i++ {
+		avg    = (avg * float64(period -1) + values[i]) / float64(period)
+		res[i] = avg
+	}
+
+	return res
+}
+
+//=============================================================================
@@ -0,0 +1,218 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package indicator
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bit-fever/data-collector/pkg/ds"
+)
+
+//=============================================================================
+
+const (
+	NameSMA       = "sma"
+	NameEMA       = "ema"
+	NameATR       = "atr"
+	NameRSI       = "rsi"
+	NameBollinger = "bollinger"
+	NameVWAP      = "vwap"
+)
+
+//--- Smoothed indicators (EMA, ATR, RSI) need more bars than their period to converge
+const SmoothingFactor = 3
+
+//=============================================================================
+//===
+//=== Spec
+//===
+//=============================================================================
+
+type Spec struct {
+	Text    string
+	Name    string
+	Period  int
+	StdDevs float64
+}
+
+//=============================================================================
+
+type Series struct {
+	Name   string
+	Values []float64
+}
+
+//=============================================================================
+//--- Parses a list like: sma(20),ema(50),bollinger(20,2),vwap(session)
+
+func Parse(text string) ([]*Spec, error) {
+	var list []*Spec
+
+	for _, item := range splitList(text) {
+		spec, err := parseSpec(item)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, spec)
+	}
+
+	return list, nil
+}
+
+//=============================================================================
+//--- Number of bars needed before the first value is reliable
+
+func (s *Spec) WarmupBars() int {
+	switch s.Name {
+		case NameEMA, NameATR, NameRSI:
+			return s.Period * SmoothingFactor
+		case NameVWAP:
+			return 0
+	}
+
+	return s.Period
+}
+
+//=============================================================================
+//--- Location is used to find sessions' boundaries
+
+func (s *Spec) Compute(points []*ds.DataPoint, loc *time.Location) []*Series {
+	switch s.Name {
+		case NameSMA:
+			return s.newSeries("", SMA(Closes(points), s.Period))
+		case NameEMA:
+			return s.newSeries("", EMA(Closes(points), s.Period))
+		case NameATR:
+			return s.newSeries("", ATR(points, s.Period))
+		case NameRSI:
+			return s.newSeries("", RSI(Closes(points), s.Period))
+		case NameBollinger:
+			middle, upper, lower := Bollinger(Closes(points), s.Period, s.StdDevs)
+			return append(append(s.newSeries(".middle", middle), s.newSeries(".upper", upper)...), s.newSeries(".lower", lower)...)
+	}
+
+	return s.newSeries("", VWAP(points, loc))
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func (s *Spec) newSeries(suffix string, values []float64) []*Series {
+	return []*Series{
+		{
+			Name  : s.Text + suffix,
+			Values: values,
+		},
+	}
+}
+
+//=============================================================================
+//--- Splits on commas that are not inside parenthesis
+
+func splitList(text string) []string {
+	var list []string
+
+	depth := 0
+	start := 0
+
+	for i, ch := range text {
+		switch ch {
+			case '(': depth++
+			case ')': depth--
+			case ',':
+				if depth == 0 {
+					list  = append(list, text[start:i])
+					start = i +1
+				}
+		}
+	}
+
+	return append(list, text[start:])
+}
+
+//=============================================================================
+
+func parseSpec(text string) (*Spec, error) {
+	text = strings.ToLower(strings.ReplaceAll(text, " ", ""))
+
+	open := strings.Index(text, "(")
+	if open <= 0 || !strings.HasSuffix(text, ")") {
+		return nil, errors.New("bad indicator: "+ text)
+	}
+
+	spec := &Spec{
+		Text: text,
+		Name: text[:open],
+	}
+
+	var args []string
+	if argText := text[open+1 : len(text)-1]; argText != "" {
+		args = strings.Split(argText, ",")
+	}
+
+	switch spec.Name {
+		case NameSMA, NameEMA, NameATR, NameRSI:
+			if len(args) != 1 {
+				return nil, errors.New("indicator requires a period: "+ text)
+			}
+
+		case NameBollinger:
+			if len(args) != 2 {
+				return nil, errors.New("indicator requires a period and a number of standard deviations: "+ text)
+			}
+
+			stdDevs, err := strconv.ParseFloat(args[1], 64)
+			if err != nil || stdDevs <= 0 {
+				return nil, errors.New("bad number of standard deviations: "+ text)
+			}
+			spec.StdDevs = stdDevs
+
+		case NameVWAP:
+			if len(args) > 1 || (len(args) == 1 && args[0] != "session") {
+				return nil, errors.New("only session vwap is supported: "+ text)
+			}
+			return spec, nil
+
+		default:
+			return nil, errors.New("unknown indicator: "+ text)
+	}
+
+	period, err := strconv.Atoi(args[0])
+	if err != nil || period < 1 || period > 1000 {
+		return nil, errors.New("period must be in the range 1..1000: "+ text)
+	}
+
+	spec.Period = period
+	return spec, nil
+}
+
+//=============================================================================
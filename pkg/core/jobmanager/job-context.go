@@ -167,6 +167,7 @@ func (jc *JobContext) sendRollRecalcMessage() error {
 
 	job := &rollover.RecalcJob{
 		DataBlockId: sj.block.Id,
+		Trigger    : db.RHTriggerDownload,
 	}
 
 	err := msg.SendMessage(msg.ExCollector, msg.SourceRollRecalcJob, msg.TypeCreate, job)
@@ -0,0 +1,217 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package rollover
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/bit-fever/data-collector/pkg/db"
+	"github.com/bit-fever/data-collector/pkg/ds"
+)
+
+//=============================================================================
+
+const RollWindow = 24 * time.Hour
+
+//--- Above this dispersion/price ratio the delta is flagged as low confidence
+const LowConfidenceRatio = 0.0005
+
+//=============================================================================
+
+type barPair struct {
+	curr *ds.DataPoint
+	next *ds.DataPoint
+}
+
+//=============================================================================
+//--- Returns nil if the two contracts have no common bar
+
+func estimateRollDelta(prices1, prices2 []*ds.DataPoint, estimator db.DPRollEstimator) *rollMatch {
+	pairs := matchBars(prices1, prices2)
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	switch estimator {
+		case db.DPRollEstimatorMedian:
+			return estimateMedian(pairs)
+		case db.DPRollEstimatorVWMean:
+			return estimateVWMean(pairs)
+	}
+
+	return estimateFirst(pairs)
+}
+
+//=============================================================================
+
+func isLowConfidence(m *rollMatch) bool {
+	if m.currPrice == 0 {
+		return false
+	}
+
+	return m.dispersion / math.Abs(m.currPrice) > LowConfidenceRatio
+}
+
+//=============================================================================
+//--- Collects all bars with the same timestamp inside the roll window that
+//--- starts with the first common bar
+
+func matchBars(prices1, prices2 []*ds.DataPoint) []*barPair {
+	var pairs []*barPair
+	var endTime time.Time
+
+	currIdx := 0
+	nextIdx := 0
+
+	for currIdx<len(prices1) && nextIdx<len(prices2) {
+		p1 := prices1[currIdx]
+		p2 := prices2[nextIdx]
+
+		res := p1.Time.Compare(p2.Time)
+
+		if res == -1 {
+			currIdx++
+		} else if res == 1 {
+			nextIdx++
+		} else {
+			if len(pairs) == 0 {
+				endTime = p1.Time.Add(RollWindow)
+			} else if p1.Time.After(endTime) {
+				break
+			}
+
+			pairs = append(pairs, &barPair{ curr: p1, next: p2 })
+			currIdx++
+			nextIdx++
+		}
+	}
+
+	return pairs
+}
+
+//=============================================================================
+
+func estimateFirst(pairs []*barPair) *rollMatch {
+	p := pairs[0]
+
+	return &rollMatch{
+		date     : p.curr.Time,
+		delta    : p.next.Close - p.curr.Close,
+		bars     : 1,
+		currPrice: p.curr.Close,
+		nextPrice: p.next.Close,
+	}
+}
+
+//=============================================================================
+//--- Median of the differences, with the median absolute deviation as dispersion
+
+func estimateMedian(pairs []*barPair) *rollMatch {
+	diffs := make([]float64, len(pairs))
+	for i, p := range pairs {
+		diffs[i] = p.next.Close - p.curr.Close
+	}
+
+	delta := median(diffs)
+
+	devs := make([]float64, len(diffs))
+	for i, d := range diffs {
+		devs[i] = math.Abs(d - delta)
+	}
+
+	return &rollMatch{
+		date      : pairs[0].curr.Time,
+		delta     : delta,
+		dispersion: median(devs),
+		bars      : len(pairs),
+		currPrice : pairs[0].curr.Close,
+		nextPrice : pairs[0].next.Close,
+	}
+}
+
+//=============================================================================
+//--- Mean of the differences weighted by the volume of the less liquid leg,
+//--- with the weighted standard deviation as dispersion. If there is no volume
+//--- at all, all bars get the same weight
+
+func estimateVWMean(pairs []*barPair) *rollMatch {
+	weights := make([]float64, len(pairs))
+	totWeight := 0.0
+
+	for i, p := range pairs {
+		v1 := p.curr.UpVolume + p.curr.DownVolume
+		v2 := p.next.UpVolume + p.next.DownVolume
+		weights[i] = float64(min(v1, v2))
+		totWeight += weights[i]
+	}
+
+	if totWeight == 0 {
+		for i := range weights {
+			weights[i] = 1
+		}
+		totWeight = float64(len(weights))
+	}
+
+	delta := 0.0
+	for i, p := range pairs {
+		delta += weights[i] * (p.next.Close - p.curr.Close)
+	}
+	delta /= totWeight
+
+	variance := 0.0
+	for i, p := range pairs {
+		d := p.next.Close - p.curr.Close - delta
+		variance += weights[i] * d * d
+	}
+	variance /= totWeight
+
+	return &rollMatch{
+		date      : pairs[0].curr.Time,
+		delta     : delta,
+		dispersion: math.Sqrt(variance),
+		bars      : len(pairs),
+		currPrice : pairs[0].curr.Close,
+		nextPrice : pairs[0].next.Close,
+	}
+}
+
+//=============================================================================
+
+func median(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	n := len(sorted)
+	if n % 2 == 1 {
+		return sorted[n/2]
+	}
+
+	return (sorted[n/2 -1] + sorted[n/2]) / 2
+}
+
+//=============================================================================
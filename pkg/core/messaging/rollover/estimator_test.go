@@ -0,0 +1,112 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package rollover
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bit-fever/data-collector/pkg/db"
+	"github.com/bit-fever/data-collector/pkg/ds"
+)
+
+//=============================================================================
+
+var base = time.Date(2024,3,14,0,0,0,0, time.UTC)
+
+//--- The spike at hour 2 must not move the median estimate
+
+var currLeg = []*ds.DataPoint{
+	{Time:base,                  Close:100,UpVolume:10, DownVolume:10},
+	{Time:base.Add(1*time.Hour), Close:101,UpVolume:10, DownVolume:10},
+	{Time:base.Add(2*time.Hour), Close:102,UpVolume:10, DownVolume:10},
+	{Time:base.Add(3*time.Hour), Close:103,UpVolume:10, DownVolume:10},
+	{Time:base.Add(30*time.Hour),Close:104,UpVolume:10, DownVolume:10},
+}
+
+var nextLeg = []*ds.DataPoint{
+	{Time:base,                  Close:102,UpVolume:100,DownVolume:100},
+	{Time:base.Add(1*time.Hour), Close:103,UpVolume:100,DownVolume:100},
+	{Time:base.Add(2*time.Hour), Close:112,UpVolume:100,DownVolume:100},
+	{Time:base.Add(3*time.Hour), Close:105,UpVolume:100,DownVolume:100},
+	{Time:base.Add(30*time.Hour),Close:200,UpVolume:100,DownVolume:100},
+}
+
+//=============================================================================
+
+func TestRollEstimators(t *testing.T) {
+	m := estimateRollDelta(currLeg, nextLeg, db.DPRollEstimatorFirst)
+	if m.delta != 2 || m.bars != 1 {
+		t.Errorf("First estimator: expected delta 2 on 1 bar but got %v on %v", m.delta, m.bars)
+	}
+
+	m = estimateRollDelta(currLeg, nextLeg, db.DPRollEstimatorMedian)
+	if m.delta != 2 || m.bars != 4 || m.dispersion != 0 {
+		t.Errorf("Median estimator: expected delta 2 on 4 bars but got %v on %v (dispersion %v)", m.delta, m.bars, m.dispersion)
+	}
+
+	m = estimateRollDelta(currLeg, nextLeg, db.DPRollEstimatorVWMean)
+	if m.delta != 4 || m.bars != 4 {
+		t.Errorf("VWMean estimator: expected delta 4 on 4 bars but got %v on %v", m.delta, m.bars)
+	}
+
+	if !isLowConfidence(m) {
+		t.Errorf("VWMean estimator: expected low confidence with dispersion %v", m.dispersion)
+	}
+
+	if !m.date.Equal(base) {
+		t.Errorf("Rollover date must be the first common bar. Expected %v but got %v", base, m.date)
+	}
+}
+
+//=============================================================================
+
+//--- The next contract trades more from the second day
+
+func TestFindVolumeCrossover(t *testing.T) {
+	curr := []*ds.DataPoint{
+		{Time:base,                  UpVolume:500, DownVolume:500},
+		{Time:base.Add(24*time.Hour),UpVolume:150, DownVolume:150},
+		{Time:base.Add(26*time.Hour),UpVolume:150, DownVolume:150},
+		{Time:base.Add(48*time.Hour),UpVolume:100, DownVolume:100},
+	}
+
+	next := []*ds.DataPoint{
+		{Time:base,                  UpVolume:400, DownVolume:400},
+		{Time:base.Add(24*time.Hour),UpVolume:700, DownVolume:0  },
+		{Time:base.Add(48*time.Hour),UpVolume:900, DownVolume:900},
+	}
+
+	date := findVolumeCrossover(curr, next)
+	if date == nil || !date.Equal(base.Add(24*time.Hour)) {
+		t.Errorf("Expected crossover on %v but got %v", base.Add(24*time.Hour), date)
+	}
+
+	if date = findVolumeCrossover(curr, next[:1]); date != nil {
+		t.Errorf("Expected no crossover but got %v", date)
+	}
+}
+
+//=============================================================================
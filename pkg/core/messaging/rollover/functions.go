@@ -25,21 +25,82 @@ THE SOFTWARE.
 package rollover
 
 import (
+	"slices"
 	"time"
 
 	"github.com/bit-fever/data-collector/pkg/db"
+	"github.com/bit-fever/data-collector/pkg/ds"
 )
 
 //=============================================================================
 
+//--- The volume trigger looks for the crossover in this period before expiration
+const VolumeRollDays = 30
+
+//=============================================================================
+//--- The volume trigger falls back to sd4 when the volume crossover is unknown
+
 func calcRolloverDate(expirDate time.Time, rollTrigger db.DPRollTrigger) time.Time {
 	switch rollTrigger {
-		case db.DPRollTriggerSD4 : return calcRolloverDateByDays(expirDate, 4)
-		case db.DPRollTriggerSD6 : return calcRolloverDateByDays(expirDate, 6)
+		case db.DPRollTriggerSD4    : return calcRolloverDateByDays(expirDate, 4)
+		case db.DPRollTriggerSD6    : return calcRolloverDateByDays(expirDate, 6)
+		case db.DPRollTriggerVolume : return calcRolloverDateByDays(expirDate, 4)
 		default: return calcRolloverDateByDays(expirDate, 30)
 	}
 }
 
+//=============================================================================
+//--- An explicit roll date (i.e. first notice day) always wins over the trigger rule
+
+func calcInstrumentRolloverDate(die *db.DataInstrumentExt, rollTrigger db.DPRollTrigger, overrides map[uint]time.Time) time.Time {
+	if date, found := overrides[die.Id]; found {
+		return date
+	}
+
+	return calcRolloverDate(*die.ExpirationDate, rollTrigger)
+}
+
+//=============================================================================
+//--- Returns the first day in which the next contract traded more than the
+//--- current one, or nil if it never happened. Volumes are summed per UTC day
+
+func findVolumeCrossover(prices1, prices2 []*ds.DataPoint) *time.Time {
+	volumes1 := calcDailyVolumes(prices1)
+	volumes2 := calcDailyVolumes(prices2)
+
+	var days []time.Time
+	for day := range volumes2 {
+		if _, found := volumes1[day]; found {
+			days = append(days, day)
+		}
+	}
+
+	slices.SortFunc(days, func(a, b time.Time) int {
+		return a.Compare(b)
+	})
+
+	for _, day := range days {
+		if volumes2[day] > volumes1[day] {
+			return &day
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
+
+func calcDailyVolumes(prices []*ds.DataPoint) map[time.Time]int {
+	volumes := map[time.Time]int{}
+
+	for _, dp := range prices {
+		y,m,d := dp.Time.UTC().Date()
+		volumes[time.Date(y, m, d, 0, 0, 0, 0, time.UTC)] += dp.UpVolume + dp.DownVolume
+	}
+
+	return volumes
+}
+
 //=============================================================================
 
 func calcRolloverDateByDays(expirDate time.Time, days int) time.Time {
@@ -47,3 +108,45 @@ func calcRolloverDateByDays(expirDate time.Time, days int) time.Time {
 }
 
 //=============================================================================
+
+func GetVirtualSettings(vi *db.DataInstrument, dp *db.DataProduct) (string, db.DPRollTrigger) {
+	if vi.VirtualMonths == "" {
+		return dp.Months, dp.RolloverTrigger
+	}
+
+	trigger := vi.VirtualRollTrigger
+	if trigger == "" {
+		trigger = dp.RolloverTrigger
+	}
+
+	return vi.VirtualMonths, trigger
+}
+
+//=============================================================================
+//--- Replaces the rollover fields of each instrument with the ones calculated for
+//--- a non default virtual instrument. Manual pins only apply to the default one
+
+func ApplyVirtualRollovers(list []*db.DataInstrument, vrs *[]db.VirtualRollover) {
+	rolls := map[uint]*db.VirtualRollover{}
+	for i := range *vrs {
+		rolls[(*vrs)[i].DataInstrumentId] = &(*vrs)[i]
+	}
+
+	for _, di := range list {
+		di.RolloverManual = false
+
+		if vr, found := rolls[di.Id]; found {
+			di.RolloverDate       = vr.RolloverDate
+			di.RolloverDelta      = vr.RolloverDelta
+			di.RolloverStatus     = vr.RolloverStatus
+			di.RolloverDispersion = vr.RolloverDispersion
+		} else {
+			di.RolloverDate       = nil
+			di.RolloverDelta      = 0
+			di.RolloverStatus     = db.DIRollStatusWaiting
+			di.RolloverDispersion = 0
+		}
+	}
+}
+
+//=============================================================================
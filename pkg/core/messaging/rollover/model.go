@@ -24,12 +24,47 @@ THE SOFTWARE.
 
 package rollover
 
+import (
+	"time"
+
+	"github.com/bit-fever/data-collector/pkg/db"
+)
+
 //=============================================================================
 
 type RecalcJob struct {
 	DataProductId uint
 	DataBlockId   uint
 	ForceRecalc   bool
+	Trigger       db.RHTrigger
+	Username      string
+}
+
+//=============================================================================
+
+type rollMatch struct {
+	date       time.Time
+	delta      float64
+	dispersion float64
+	bars       int
+	currPrice  float64
+	nextPrice  float64
+}
+
+//=============================================================================
+
+type rollSet struct {
+	virtual     *db.DataInstrument
+	months      string
+	trigger     db.DPRollTrigger
+	instruments *[]db.DataInstrumentExt
+	rollIds     map[uint]uint
+}
+
+//-----------------------------------------------------------------------------
+
+func (rs *rollSet) isDefault() bool {
+	return rs.virtual == nil || rs.virtual.VirtualMonths == ""
 }
 
 //=============================================================================
@@ -40,12 +40,12 @@ import (
 
 func Recalc(job *RecalcJob) bool {
 	if job.DataProductId != 0 {
-		return recalcForProduct(job.DataProductId)
+		return recalcForProduct(job.DataProductId, job)
 	} else {
 		list,err := getProductsToRecalc(job.DataBlockId)
 		if err == nil {
 			for _, id := range *list {
-				ok := recalcForProduct(id)
+				ok := recalcForProduct(id, job)
 				if !ok {
 					return false
 				}
@@ -76,152 +76,303 @@ func getProductsToRecalc(blockId uint) (*[]uint,error) {
 
 //=============================================================================
 
-func recalcForProduct(id uint) bool {
-	slog.Info("recalcForProduct: Starting rollover recalc", "dpId", id)
+func recalcForProduct(id uint, job *RecalcJob) bool {
+	slog.Info("recalcForProduct: Starting rollover recalc", "dpId", id, "force", job.ForceRecalc, "trigger", job.Trigger)
 
-	dp,instruments,err := getIntrumentSet(id)
+	dp,sets,overrides,err := getIntrumentSets(id)
 	if err == nil {
-		var updated []*db.DataInstrumentExt
-		var curr,next *db.DataInstrumentExt
+		for _, rs := range sets {
+			err = recalcRollSet(dp, rs, overrides, job)
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		slog.Error("recalcForProduct: Operation aborted due to error. Will retry", "dpId", id, "error", err)
+	}
+
+	slog.Info("recalcForProduct: Ending rollover recalc", "dpId", id)
+
+	return err == nil
+}
+
+//=============================================================================
+
+func recalcRollSet(dp *db.DataProduct, rs *rollSet, overrides map[uint]time.Time, job *RecalcJob) error {
+	var updated []*db.DataInstrumentExt
+	var history []*db.RolloverHistory
+	var curr,next *db.DataInstrumentExt
+	var err error
 
-		for i:=0; i<len(*instruments)-1; i++ {
-			curr = &(*instruments)[i]
-			next = &(*instruments)[i+1]
+	instruments := rs.instruments
 
-			var toUpdate bool
+	for i:=0; i<len(*instruments)-1; i++ {
+		curr = &(*instruments)[i]
+		next = &(*instruments)[i+1]
 
-			//--- Check if we have to calculate the rollover
+		//--- A rollover pinned by the user is never overwritten
 
-			shouldRecalc := curr.RolloverDate   == nil ||
-							curr.RolloverStatus == db.DIRollStatusNoData ||
-							curr.RolloverStatus == db.DIRollStatusNoMatch
+		if curr.RolloverManual {
+			continue
+		}
+
+		var toUpdate bool
+		var match *rollMatch
+		old := curr.DataInstrument
+
+		//--- Check if we have to calculate the rollover
+
+		shouldRecalc := job.ForceRecalc ||
+						curr.RolloverDate   == nil ||
+						curr.RolloverStatus == db.DIRollStatusNoData ||
+						curr.RolloverStatus == db.DIRollStatusNoMatch
 
-			if shouldRecalc {
-				if *curr.Status == db.DBStatusReady {
-					//--- First block loaded. Check the second one
+		if shouldRecalc {
+			if *curr.Status == db.DBStatusReady {
+				//--- First block loaded. Check the second one
 
-					if *next.Status == db.DBStatusReady || *next.Status == db.DBStatusSleeping {
-						toUpdate, err = calcRollover(dp, curr, next, dp.RolloverTrigger)
-						if err != nil {
-							break;
-						}
-					} else if *next.Status == db.DBStatusEmpty {
-						toUpdate = setFakeRolloverDate(curr, dp)
+				if *next.Status == db.DBStatusReady || *next.Status == db.DBStatusSleeping {
+					toUpdate, match, err = calcRollover(dp, rs.trigger, curr, next, overrides)
+					if err != nil {
+						break;
 					}
-				} else if *curr.Status == db.DBStatusEmpty {
-					toUpdate = setFakeRolloverDate(curr, dp)
+				} else if *next.Status == db.DBStatusEmpty {
+					toUpdate = setFakeRolloverDate(curr, rs.trigger, overrides)
 				}
+			} else if *curr.Status == db.DBStatusEmpty {
+				toUpdate = setFakeRolloverDate(curr, rs.trigger, overrides)
+			}
 
-				if toUpdate {
-					updated = append(updated, curr)
-				}
+			if toUpdate {
+				updated = append(updated, curr)
+				history = append(history, newRolloverHistory(job, rs, &old, curr, next, match))
 			}
 		}
+	}
 
-		err = updateRolledInstruments(updated)
+	//--- Rollovers calculated before an error are saved anyway
 
-		if err == nil {
-			err = recalcVirtualInstrumentStatus(dp, instruments)
-		}
+	errUpd := updateRolledInstruments(rs, updated, history)
+	if err == nil {
+		err = errUpd
 	}
 
-	if err != nil {
-		slog.Error("recalcForProduct: Operation aborted due to error. Will retry", "dpId", id, "error", err)
+	if err == nil {
+		err = recalcVirtualInstrumentStatus(dp, rs.virtual, instruments)
 	}
 
-	slog.Info("recalcForProduct: Ending rollover recalc", "dpId", id)
-
-	return err == nil
+	return err
 }
 
 //=============================================================================
+//--- Builds a set of rolling instruments for each virtual instrument. The default
+//--- set uses the product's months and roll trigger
 
-func getIntrumentSet(dpId uint) (*db.DataProduct,*[]db.DataInstrumentExt,error) {
-	var dp   *db.DataProduct
-	var list *[]db.DataInstrumentExt
+func getIntrumentSets(dpId uint) (*db.DataProduct,[]*rollSet,map[uint]time.Time,error) {
+	var dp        *db.DataProduct
+	var sets      []*rollSet
+	var overrides *[]db.RollOverrideFull
 
 	err2 := db.RunInTransaction(func(tx *gorm.DB) error {
 		var err error
 		dp,err = db.GetDataProductById(tx, dpId)
-		if err == nil {
-			if dp == nil {
-				err = errors.New("No data product found : "+ strconv.Itoa(int(dpId)))
-			} else {
-				list,err = db.GetRollingDataInstrumentsByProductId(tx, dpId, dp.Months)
+		if err != nil {
+			return err
+		}
+		if dp == nil {
+			return errors.New("No data product found : "+ strconv.Itoa(int(dpId)))
+		}
+
+		var virtuals *[]db.DataInstrument
+		virtuals,err = db.GetVirtualDataInstrumentsByProductId(tx, dpId)
+		if err != nil {
+			return err
+		}
+
+		var list *[]db.DataInstrumentExt
+		list,err = db.GetRollingDataInstrumentsByProductId(tx, dpId, dp.Months)
+		if err != nil {
+			return err
+		}
+
+		def := &rollSet{
+			months     : dp.Months,
+			trigger    : dp.RolloverTrigger,
+			instruments: list,
+		}
+		sets = append(sets, def)
+
+		for i := range *virtuals {
+			vi := &(*virtuals)[i]
+			if vi.VirtualMonths == "" {
+				def.virtual = vi
+				continue
+			}
+
+			rs, err := getVirtualRollSet(tx, dp, vi)
+			if err != nil {
+				return err
 			}
+			sets = append(sets, rs)
 		}
+
+		overrides,err = db.GetRollOverridesByProductId(tx, dpId)
 		return err
 	})
 
 	if err2 != nil {
-		return nil,nil,err2
+		return nil,nil,nil,err2
+	}
+
+	//--- Explicit roll dates, indexed by data instrument
+
+	dates := map[uint]time.Time{}
+	for _, ro := range *overrides {
+		dates[ro.DataInstrumentId] = ro.RolloverDate
 	}
 
-	return dp,list,nil
+	return dp,sets,dates,nil
 }
 
 //=============================================================================
 
-func calcRollover(dp *db.DataProduct, curr, next *db.DataInstrumentExt, rollTrigger db.DPRollTrigger) (bool,error) {
-	startRollDate := calcRolloverDate(*curr.ExpirationDate, rollTrigger)
+func getVirtualRollSet(tx *gorm.DB, dp *db.DataProduct, vi *db.DataInstrument) (*rollSet, error) {
+	months, trigger := GetVirtualSettings(vi, dp)
+
+	list, err := db.GetRollingDataInstrumentsByProductId(tx, dp.Id, months)
+	if err != nil {
+		return nil, err
+	}
+
+	vrs, err := db.GetVirtualRolloversByVirtualId(tx, vi.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	var instruments []*db.DataInstrument
+	for i := range *list {
+		instruments = append(instruments, &(*list)[i].DataInstrument)
+	}
+	ApplyVirtualRollovers(instruments, vrs)
+
+	rollIds := map[uint]uint{}
+	for _, vr := range *vrs {
+		rollIds[vr.DataInstrumentId] = vr.Id
+	}
+
+	return &rollSet{
+		virtual    : vi,
+		months     : months,
+		trigger    : trigger,
+		instruments: list,
+		rollIds    : rollIds,
+	}, nil
+}
+
+//=============================================================================
+
+func calcRollover(dp *db.DataProduct, trigger db.DPRollTrigger, curr, next *db.DataInstrumentExt, overrides map[uint]time.Time) (bool,*rollMatch,error) {
+	startRollDate := calcInstrumentRolloverDate(curr, trigger, overrides)
+
+	if _, found := overrides[curr.Id]; !found && trigger == db.DPRollTriggerVolume {
+		date, err := calcVolumeRolloverDate(dp, curr, next)
+		if err != nil {
+			return false, nil, err
+		}
+
+		if date != nil {
+			startRollDate = *date
+		}
+	}
 
 	if *next.Status == db.DBStatusSleeping && time.Now().Sub(startRollDate) <8*time.Hour {
 		//--- If the startRollDate is within 8 hours behind now, let's skip
-		return false, nil
+		return false, nil, nil
 	}
 
-	return true, calcRolloverDelta(dp, curr, next, startRollDate)
+	match, err := calcRolloverDelta(dp, curr, next, startRollDate)
+	return true, match, err
 }
 
 //=============================================================================
 
-func calcRolloverDelta(dp *db.DataProduct, curr, next *db.DataInstrumentExt, startRollDate time.Time) error {
-	prices1,err1 := getPrices(dp.SystemCode, curr.Symbol, startRollDate)
-	prices2,err2 := getPrices(dp.SystemCode, next.Symbol, startRollDate)
+func calcRolloverDelta(dp *db.DataProduct, curr, next *db.DataInstrumentExt, startRollDate time.Time) (*rollMatch,error) {
+	timeframe := dp.RollTimeframe
+	if timeframe == "" {
+		timeframe = "60m"
+	}
+
+	prices1,err1 := getPrices(dp.SystemCode, curr.Symbol, timeframe, startRollDate)
+	prices2,err2 := getPrices(dp.SystemCode, next.Symbol, timeframe, startRollDate)
 	if err1 != nil {
-		return errors.New("Failed to get prices from current: "+err1.Error())
+		return nil, errors.New("Failed to get prices from current: "+err1.Error())
 	}
 	if err2 != nil {
-		return errors.New("Failed to get prices from next: "+err2.Error())
+		return nil, errors.New("Failed to get prices from next: "+err2.Error())
 	}
 
-	currIdx := 0
-	nextIdx := 0
-
-	for currIdx<len(prices1) && nextIdx<len(prices2) {
-		p1 := prices1[currIdx]
-		p2 := prices2[nextIdx]
+	match := estimateRollDelta(prices1, prices2, dp.RollEstimator)
+	if match != nil {
+		curr.RolloverDate       = &match.date
+		curr.RolloverDelta      = match.delta
+		curr.RolloverDispersion = match.dispersion
+		curr.RolloverStatus     = db.DIRollStatusReady
 
-		res := p1.Time.Compare(p2.Time)
-
-		if res == -1 {
-			currIdx++
-		} else if res == 1 {
-			nextIdx++
-		} else {
-			//--- Ok, found the same time. Now calc delta
-			curr.RolloverDate   = &p1.Time
-			curr.RolloverDelta  = p2.Close - p1.Close
-			curr.RolloverStatus = db.DIRollStatusReady
-			return nil
+		if isLowConfidence(match) {
+			slog.Warn("calcRolloverDelta: Rollover delta has a high dispersion", "dpId", dp.Id, "currId", curr.Id, "delta", match.delta, "dispersion", match.dispersion)
+			curr.RolloverStatus = db.DIRollStatusLowConfidence
 		}
+
+		return match, nil
 	}
 
 	slog.Error("calcRolloverDelta: Cannot find any rollover delta", "dpId", dp.Id, "currId", curr.Id, "nextId", next.Id, "startRollDate", startRollDate)
 
-	curr.RolloverStatus = db.DIRollStatusNoMatch
-	curr.RolloverDelta  = 0
-	curr.RolloverDate   = &startRollDate
+	curr.RolloverStatus     = db.DIRollStatusNoMatch
+	curr.RolloverDelta      = 0
+	curr.RolloverDispersion = 0
+	curr.RolloverDate       = &startRollDate
+
+	return nil, nil
+}
+
+//=============================================================================
+//--- Looks for the volume crossover in the last days before expiration
+
+func calcVolumeRolloverDate(dp *db.DataProduct, curr, next *db.DataInstrumentExt) (*time.Time,error) {
+	to   := *curr.ExpirationDate
+	from := to.AddDate(0, 0, -VolumeRollDays)
+
+	prices1,err1 := getPricesInRange(dp.SystemCode, curr.Symbol, "60m", from, to)
+	prices2,err2 := getPricesInRange(dp.SystemCode, next.Symbol, "60m", from, to)
+	if err1 != nil {
+		return nil, errors.New("Failed to get volumes from current: "+err1.Error())
+	}
+	if err2 != nil {
+		return nil, errors.New("Failed to get volumes from next: "+err2.Error())
+	}
+
+	date := findVolumeCrossover(prices1, prices2)
+	if date == nil {
+		slog.Warn("calcVolumeRolloverDate: No volume crossover found. Using fallback date", "dpId", dp.Id, "currId", curr.Id)
+	}
+
+	return date, nil
+}
+
+//=============================================================================
 
-	return nil
+func getPrices(systemCode, symbol, timeframe string, from time.Time) ([]*ds.DataPoint, error){
+	return getPricesInRange(systemCode, symbol, timeframe, from, from.Add(5 * 24 * time.Hour))
 }
 
 //=============================================================================
 
-func getPrices(systemCode, symbol string, from time.Time) ([]*ds.DataPoint, error){
-	config := ds.NewDataConfig(systemCode, symbol,"60m")
+func getPricesInRange(systemCode, symbol, timeframe string, from, to time.Time) ([]*ds.DataPoint, error){
+	config := ds.NewDataConfig(systemCode, symbol, timeframe)
 	da     := ds.NewDataAggregator(nil,nil)
-	to     := from.Add(5 * 24 * time.Hour)
 
 	err    := ds.GetDataPoints(from, to, config, time.UTC, da)
 	if err != nil {
@@ -233,15 +384,26 @@ func getPrices(systemCode, symbol string, from time.Time) ([]*ds.DataPoint, erro
 
 //=============================================================================
 
-func updateRolledInstruments(list []*db.DataInstrumentExt) error {
+func updateRolledInstruments(rs *rollSet, list []*db.DataInstrumentExt, history []*db.RolloverHistory) error {
 	if len(list) == 0 {
 		return nil
 	}
 
 	err := db.RunInTransaction(func(tx *gorm.DB) error {
 		for _, die := range list {
-			i := convertInstrument(die)
-			err := db.UpdateDataInstrument(tx, i)
+			var err error
+			if rs.isDefault() {
+				err = db.UpdateDataInstrument(tx, convertInstrument(die))
+			} else {
+				err = db.SetVirtualRollover(tx, convertVirtualRollover(rs, die))
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, rh := range history {
+			err := db.AddRolloverHistory(tx, rh)
 			if err != nil {
 				return err
 			}
@@ -261,34 +423,87 @@ func updateRolledInstruments(list []*db.DataInstrumentExt) error {
 
 func convertInstrument(die *db.DataInstrumentExt) *db.DataInstrument {
 	return &db.DataInstrument{
-		Id:             die.Id,
-		DataProductId:  die.DataProductId,
-		DataBlockId:    die.DataBlockId,
-		Symbol:         die.Symbol,
-		Name:           die.Name,
-		ExpirationDate: die.ExpirationDate,
-		RolloverDate:   die.RolloverDate,
-		Continuous:     die.Continuous,
-		Month:          die.Month,
-		RolloverDelta:  die.RolloverDelta,
-		RolloverStatus: die.RolloverStatus,
+		Id:                 die.Id,
+		DataProductId:      die.DataProductId,
+		DataBlockId:        die.DataBlockId,
+		Symbol:             die.Symbol,
+		Name:               die.Name,
+		ExpirationDate:     die.ExpirationDate,
+		RolloverDate:       die.RolloverDate,
+		Continuous:         die.Continuous,
+		Month:              die.Month,
+		RolloverDelta:      die.RolloverDelta,
+		RolloverStatus:     die.RolloverStatus,
+		RolloverDispersion: die.RolloverDispersion,
+		RolloverManual:     die.RolloverManual,
+	}
+}
+
+//=============================================================================
+
+func convertVirtualRollover(rs *rollSet, die *db.DataInstrumentExt) *db.VirtualRollover {
+	return &db.VirtualRollover{
+		Id                 : rs.rollIds[die.Id],
+		VirtualInstrumentId: rs.virtual.Id,
+		DataInstrumentId   : die.Id,
+		RolloverDate       : die.RolloverDate,
+		RolloverDelta      : die.RolloverDelta,
+		RolloverStatus     : die.RolloverStatus,
+		RolloverDispersion : die.RolloverDispersion,
 	}
 }
 
 //=============================================================================
 
-func setFakeRolloverDate(die *db.DataInstrumentExt, dp *db.DataProduct) bool {
-	rollDate          := calcRolloverDate(*die.ExpirationDate, dp.RolloverTrigger)
-	die.RolloverDate   = &rollDate
-	die.RolloverDelta  = 0
-	die.RolloverStatus = db.DIRollStatusNoData
+func newRolloverHistory(job *RecalcJob, rs *rollSet, old *db.DataInstrument, curr, next *db.DataInstrumentExt, match *rollMatch) *db.RolloverHistory {
+	username := job.Username
+	if username == "" {
+		username = "system"
+	}
+
+	rh := &db.RolloverHistory{
+		DataProductId   : curr.DataProductId,
+		DataInstrumentId: curr.Id,
+		Trigger         : job.Trigger,
+		Username        : username,
+		OldDate         : old.RolloverDate,
+		OldDelta        : old.RolloverDelta,
+		OldStatus       : old.RolloverStatus,
+		NewDate         : curr.RolloverDate,
+		NewDelta        : curr.RolloverDelta,
+		NewStatus       : curr.RolloverStatus,
+		NextSymbol      : next.Symbol,
+	}
+
+	if !rs.isDefault() {
+		rh.VirtualInstrumentId = rs.virtual.Id
+	}
+
+	if match != nil {
+		rh.MatchedBars = match.bars
+		rh.Dispersion  = match.dispersion
+		rh.CurrPrice   = match.currPrice
+		rh.NextPrice   = match.nextPrice
+	}
+
+	return rh
+}
+
+//=============================================================================
+
+func setFakeRolloverDate(die *db.DataInstrumentExt, trigger db.DPRollTrigger, overrides map[uint]time.Time) bool {
+	rollDate              := calcInstrumentRolloverDate(die, trigger, overrides)
+	die.RolloverDate       = &rollDate
+	die.RolloverDelta      = 0
+	die.RolloverDispersion = 0
+	die.RolloverStatus     = db.DIRollStatusNoData
 
 	return true
 }
 
 //=============================================================================
 
-func recalcVirtualInstrumentStatus(dp *db.DataProduct, instruments *[]db.DataInstrumentExt) error {
+func recalcVirtualInstrumentStatus(dp *db.DataProduct, vi *db.DataInstrument, instruments *[]db.DataInstrumentExt) error {
 	var emptyDie, noMatchDie *db.DataInstrumentExt
 
 	for _, die := range *instruments {
@@ -310,19 +525,13 @@ func recalcVirtualInstrumentStatus(dp *db.DataProduct, instruments *[]db.DataIns
 		}
 	}
 
-	var vi *db.DataInstrument
-
 	err := db.RunInTransaction(func(tx *gorm.DB) error {
-		var err error
-		vi,err=db.GetVirtualDataInstrumentByProductId(tx, dp.Id)
+		if vi == nil {
+			return errors.New("Cannot find Virtual Data instrument for product with id: "+ strconv.Itoa(int(dp.Id)))
+		}
+		err := sendEventToUser(dp, instruments, emptyDie, noMatchDie, vi)
 		if err == nil {
-			if vi == nil {
-				return errors.New("Cannot find Virtual Data instrument for product with id: "+ strconv.Itoa(int(dp.Id)))
-			}
-			err = sendEventToUser(dp, instruments, emptyDie, noMatchDie, vi)
-			if err == nil {
-				return db.UpdateDataInstrument(tx, vi)
-			}
+			return db.UpdateDataInstrument(tx, vi)
 		}
 		return err
 	})
@@ -330,7 +539,7 @@ func recalcVirtualInstrumentStatus(dp *db.DataProduct, instruments *[]db.DataIns
 	if err != nil {
 		slog.Error("recalcProductStatus: Failed to set data product status", "error", err)
 	} else {
-		slog.Info("recalcProductStatus: Data product is ready", "dpId", dp.Id, "root", dp.Symbol)
+		slog.Info("recalcProductStatus: Data product is ready", "dpId", dp.Id, "root", dp.Symbol, "virtual", vi.Symbol)
 	}
 
 	return err
@@ -88,6 +88,7 @@ type DataProductMessage struct {
 	DataProduct DataProduct `json:"dataProduct"`
 	Connection  Connection  `json:"connection"`
 	Exchange    Exchange    `json:"exchange"`
+	Currency    Currency    `json:"currency"`
 }
 
 //=============================================================================
@@ -103,6 +104,7 @@ type BrokerProduct struct {
 	Name             string   `json:"name"`
 	PointValue       float32  `json:"pointValue"`
 	CostPerOperation float32  `json:"costPerOperation"`
+	TickSize         float32  `json:"tickSize"`
 }
 
 //=============================================================================
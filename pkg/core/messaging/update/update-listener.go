@@ -52,6 +52,10 @@ func HandleUpdateMessage(m *msg.Message) bool {
 			return addDataProduct(&dpm)
 		}
 
+		if m.Type == msg.TypeUpdate {
+			return updateDataProduct(&dpm)
+		}
+
 	} else if m.Source == msg.SourceBrokerProduct {
 		bpm := BrokerProductMessage{}
 		err := json.Unmarshal(m.Entity, &bpm)
@@ -89,6 +93,9 @@ func addDataProduct(dpm *DataProductMessage) bool {
 		pd.Timezone             = dpm.Exchange.Timezone
 		pd.Months               = dpm.DataProduct.Months
 		pd.RolloverTrigger      = dpm.DataProduct.RolloverTrigger
+		pd.RollEstimator        = db.DPRollEstimatorFirst
+		pd.RollTimeframe        = "60m"
+		pd.CurrencyCode         = dpm.Currency.Code
 		pd.Status               = db.DPStatusReady
 
 		if !pd.SupportsMultipleData {
@@ -111,6 +118,31 @@ func addDataProduct(dpm *DataProductMessage) bool {
 	return err == nil
 }
 
+//=============================================================================
+//--- Only the currency is updated, to fill it in products created before it was
+//--- sent. Other changes would affect the rollover and are ignored
+
+func updateDataProduct(dpm *DataProductMessage) bool {
+	slog.Info("updateDataProduct: Data product change received", "id", dpm.DataProduct.Id)
+
+	if dpm.Currency.Code == "" {
+		slog.Info("updateDataProduct: No currency in message. Skipping")
+		return true
+	}
+
+	err := db.RunInTransaction(func(tx *gorm.DB) error {
+		return db.UpdateDataProductCurrency(tx, dpm.DataProduct.Id, dpm.Currency.Code)
+	})
+
+	if err != nil {
+		slog.Error("Raised error while processing message")
+	} else {
+		slog.Info("updateDataProduct: Operation complete")
+	}
+
+	return err == nil
+}
+
 //=============================================================================
 //--- If the pointValue or costPerOperation change, it is wise to invalidate the results
 //--- of the current bias analyses
@@ -128,9 +160,15 @@ func setBrokerProduct(bpm *BrokerProductMessage) bool {
 		bp.Name             = bpm.BrokerProduct.Name
 		bp.PointValue       = bpm.BrokerProduct.PointValue
 		bp.CostPerOperation = bpm.BrokerProduct.CostPerOperation
+		bp.TickSize         = bpm.BrokerProduct.TickSize
 		bp.CurrencyCode     = bpm.Currency.Code
 
-		return db.UpdateBrokerProduct(tx, bp)
+		err := db.UpdateBrokerProduct(tx, bp)
+		if err != nil {
+			return err
+		}
+
+		return fillProductsCurrency(tx, bp)
 	})
 
 	if err != nil {
@@ -143,3 +181,52 @@ func setBrokerProduct(bpm *BrokerProductMessage) bool {
 }
 
 //=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+//--- Products created before the currency was added to the messages don't have
+//--- it. The currency is taken from the broker products with the same symbol, if
+//--- they all agree
+
+func fillProductsCurrency(tx *gorm.DB, bp *db.BrokerProduct) error {
+	products, err := db.GetDataProductsWithoutCurrency(tx, bp.Username, bp.Symbol)
+	if err != nil || len(*products) == 0 {
+		return err
+	}
+
+	list, err := db.GetBrokerProductsBySymbol(tx, bp.Username, bp.Symbol)
+	if err != nil {
+		return err
+	}
+
+	currency := ""
+
+	for _, p := range *list {
+		if p.CurrencyCode != "" {
+			if currency != "" && currency != p.CurrencyCode {
+				slog.Warn("fillProductsCurrency: Broker products disagree on currency. Skipping", "symbol", bp.Symbol)
+				return nil
+			}
+
+			currency = p.CurrencyCode
+		}
+	}
+
+	if currency == "" {
+		return nil
+	}
+
+	for _, dp := range *products {
+		slog.Info("fillProductsCurrency: Setting currency of data product", "id", dp.Id, "currency", currency)
+
+		err = db.UpdateDataProductCurrency(tx, dp.Id, currency)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
@@ -295,6 +295,7 @@ func calcLoadTo(expDate *time.Time) datatype.IntDate {
 func sendRollRecalcMessage(id uint) error {
 	job := &rollover.RecalcJob{
 		DataProductId: id,
+		Trigger      : db.RHTriggerInventory,
 	}
 
 	err := msg.SendMessage(msg.ExCollector, msg.SourceRollRecalcJob, msg.TypeCreate, job)
@@ -0,0 +1,151 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package synthetic
+
+import (
+	"math"
+
+	"github.com/bit-fever/data-collector/pkg/ds"
+)
+
+//=============================================================================
+//--- Aligns the legs on their timestamps (only bars present in all legs are
+//--- kept) and evaluates the expression on open and close. The legs' highs and
+//--- lows don't happen at the same time, so they don't give the spread's range:
+//--- High and Low are the max and min of open and close. Volumes and ticks are
+//--- the minimum among the legs, as the spread cannot trade more than its
+//--- less liquid leg
+
+func Combine(e *Expression, legs map[string][]*ds.DataPoint) []*ds.DataPoint {
+	result := []*ds.DataPoint{}
+
+	if len(legs) == 0 {
+		return result
+	}
+
+	indexes := map[string]int{}
+
+	for {
+		//--- Find the latest time among the current bars of all legs
+
+		var latest *ds.DataPoint
+		for symbol, list := range legs {
+			idx := indexes[symbol]
+			if idx >= len(list) {
+				return result
+			}
+
+			if latest == nil || list[idx].Time.After(latest.Time) {
+				latest = list[idx]
+			}
+		}
+
+		//--- Move all legs to that time
+
+		aligned := true
+		for symbol, list := range legs {
+			idx := indexes[symbol]
+			for idx < len(list) && list[idx].Time.Before(latest.Time) {
+				idx++
+			}
+			indexes[symbol] = idx
+
+			if idx >= len(list) {
+				return result
+			}
+
+			if !list[idx].Time.Equal(latest.Time) {
+				aligned = false
+			}
+		}
+
+		if aligned {
+			bars := map[string]*ds.DataPoint{}
+			for symbol, list := range legs {
+				bars[symbol] = list[indexes[symbol]]
+				indexes[symbol]++
+			}
+
+			dp := combineBars(e, bars)
+			if dp != nil {
+				result = append(result, dp)
+			}
+		}
+	}
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func combineBars(e *Expression, bars map[string]*ds.DataPoint) *ds.DataPoint {
+	open,  ok1 := e.Evaluate(extract(bars, func(dp *ds.DataPoint) float64 { return dp.Open  }))
+	close, ok2 := e.Evaluate(extract(bars, func(dp *ds.DataPoint) float64 { return dp.Close }))
+
+	if !ok1 || !ok2 {
+		return nil
+	}
+
+	res := &ds.DataPoint{
+		Open : open,
+		High : math.Max(open, close),
+		Low  : math.Min(open, close),
+		Close: close,
+	}
+
+	first := true
+	for _, dp := range bars {
+		if first {
+			res.Time       = dp.Time
+			res.UpVolume   = dp.UpVolume
+			res.DownVolume = dp.DownVolume
+			res.UpTicks    = dp.UpTicks
+			res.DownTicks  = dp.DownTicks
+			first = false
+		} else {
+			res.UpVolume   = min(res.UpVolume,   dp.UpVolume)
+			res.DownVolume = min(res.DownVolume, dp.DownVolume)
+			res.UpTicks    = min(res.UpTicks,    dp.UpTicks)
+			res.DownTicks  = min(res.DownTicks,  dp.DownTicks)
+		}
+	}
+
+	return res
+}
+
+//=============================================================================
+
+func extract(bars map[string]*ds.DataPoint, f func(dp *ds.DataPoint) float64) map[string]float64 {
+	values := map[string]float64{}
+	for symbol, dp := range bars {
+		values[symbol] = f(dp)
+	}
+
+	return values
+}
+
+//=============================================================================
@@ -0,0 +1,324 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package synthetic
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//=============================================================================
+//===
+//=== Expression
+//===
+//=============================================================================
+
+type Expression struct {
+	root    node
+	symbols []string
+}
+
+//=============================================================================
+//--- Grammar:
+//---    expr   := term   (('+' | '-') term)*
+//---    term   := factor (('*' | '/') factor)*
+//---    factor := ('+' | '-') factor | number | symbol | '(' expr ')'
+
+func Parse(text string) (*Expression, error) {
+	p := &parser{
+		tokens : tokenize(text),
+		symbols: map[string]bool{},
+	}
+
+	if len(p.tokens) == 0 {
+		return nil, errors.New("expression is empty")
+	}
+
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, errors.New("unexpected token: "+ p.tokens[p.pos])
+	}
+
+	e := &Expression{
+		root: root,
+	}
+
+	for s := range p.symbols {
+		e.symbols = append(e.symbols, s)
+	}
+
+	sort.Strings(e.symbols)
+
+	if len(e.symbols) == 0 {
+		return nil, errors.New("expression must reference at least one instrument")
+	}
+
+	return e, nil
+}
+
+//=============================================================================
+
+func (e *Expression) Symbols() []string {
+	return e.symbols
+}
+
+//=============================================================================
+//--- Returns false if the value cannot be calculated (i.e. division by zero)
+
+func (e *Expression) Evaluate(values map[string]float64) (float64, bool) {
+	v := e.root.eval(values)
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+
+	return v, true
+}
+
+//=============================================================================
+//===
+//=== Nodes
+//===
+//=============================================================================
+
+type node interface {
+	eval(values map[string]float64) float64
+}
+
+//=============================================================================
+
+type numberNode struct {
+	value float64
+}
+
+func (n *numberNode) eval(values map[string]float64) float64 {
+	return n.value
+}
+
+//=============================================================================
+
+type symbolNode struct {
+	symbol string
+}
+
+func (n *symbolNode) eval(values map[string]float64) float64 {
+	return values[n.symbol]
+}
+
+//=============================================================================
+
+type negateNode struct {
+	child node
+}
+
+func (n *negateNode) eval(values map[string]float64) float64 {
+	return -n.child.eval(values)
+}
+
+//=============================================================================
+
+type binaryNode struct {
+	op    byte
+	left  node
+	right node
+}
+
+func (n *binaryNode) eval(values map[string]float64) float64 {
+	l := n.left .eval(values)
+	r := n.right.eval(values)
+
+	switch n.op {
+		case '+': return l + r
+		case '-': return l - r
+		case '*': return l * r
+	}
+
+	if r == 0 {
+		return math.NaN()
+	}
+
+	return l / r
+}
+
+//=============================================================================
+//===
+//=== Parser
+//===
+//=============================================================================
+
+type parser struct {
+	tokens  []string
+	pos     int
+	symbols map[string]bool
+}
+
+//=============================================================================
+
+func (p *parser) parseExpr() (node, error) {
+	left, err := p.parseTerm()
+
+	for err == nil && (p.peek() == "+" || p.peek() == "-") {
+		op := p.next()[0]
+		var right node
+		right, err = p.parseTerm()
+		left = &binaryNode{ op: op, left: left, right: right }
+	}
+
+	return left, err
+}
+
+//=============================================================================
+
+func (p *parser) parseTerm() (node, error) {
+	left, err := p.parseFactor()
+
+	for err == nil && (p.peek() == "*" || p.peek() == "/") {
+		op := p.next()[0]
+		var right node
+		right, err = p.parseFactor()
+		left = &binaryNode{ op: op, left: left, right: right }
+	}
+
+	return left, err
+}
+
+//=============================================================================
+
+func (p *parser) parseFactor() (node, error) {
+	tok := p.next()
+
+	switch {
+		case tok == "":
+			return nil, errors.New("unexpected end of expression")
+
+		case tok == "-":
+			child, err := p.parseFactor()
+			return &negateNode{ child: child }, err
+
+		case tok == "+":
+			return p.parseFactor()
+
+		case tok == "(":
+			n, err := p.parseExpr()
+			if err == nil && p.next() != ")" {
+				err = errors.New("missing closing parenthesis")
+			}
+			return n, err
+
+		case isNumber(tok):
+			value, err := strconv.ParseFloat(tok, 64)
+			if err != nil {
+				return nil, errors.New("invalid number: "+ tok)
+			}
+			return &numberNode{ value: value }, nil
+
+		case isSymbol(tok):
+			p.symbols[tok] = true
+			return &symbolNode{ symbol: tok }, nil
+	}
+
+	return nil, errors.New("unexpected token: "+ tok)
+}
+
+//=============================================================================
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+
+	return ""
+}
+
+//=============================================================================
+
+func (p *parser) next() string {
+	tok := p.peek()
+	if tok != "" {
+		p.pos++
+	}
+
+	return tok
+}
+
+//=============================================================================
+//===
+//=== Tokenizer
+//===
+//=============================================================================
+
+func tokenize(text string) []string {
+	var tokens []string
+	var sb strings.Builder
+
+	flush := func() {
+		if sb.Len() > 0 {
+			tokens = append(tokens, sb.String())
+			sb.Reset()
+		}
+	}
+
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			flush()
+		} else if strings.ContainsRune("+-*/()", r) {
+			flush()
+			tokens = append(tokens, string(r))
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+
+	flush()
+
+	return tokens
+}
+
+//=============================================================================
+
+func isNumber(tok string) bool {
+	return unicode.IsDigit(rune(tok[0])) || tok[0] == '.'
+}
+
+//=============================================================================
+
+func isSymbol(tok string) bool {
+	for _, r := range tok {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '#' && r != '_' && r != '.' {
+			return false
+		}
+	}
+
+	return true
+}
+
+//=============================================================================
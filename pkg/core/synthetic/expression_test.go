@@ -0,0 +1,107 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package synthetic
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bit-fever/data-collector/pkg/ds"
+)
+
+//=============================================================================
+
+func TestParseAndEvaluate(t *testing.T) {
+	tests := map[string]float64{
+		"ES - 2*NQ"        : 100 - 2*30,
+		"ES/NQ"            : 100.0/30.0,
+		"-(ES - NQ) * 0.5" : -(100 - 30) * 0.5,
+		"CLZ25 - CLF26"    : 5 - 7,
+	}
+
+	values := map[string]float64{ "ES":100, "NQ":30, "CLZ25":5, "CLF26":7 }
+
+	for text, expected := range tests {
+		e, err := Parse(text)
+		if err != nil {
+			t.Fatalf("Cannot parse '%v': %v", text, err)
+		}
+
+		v, ok := e.Evaluate(values)
+		if !ok || v != expected {
+			t.Errorf("Expression '%v': expected %v but got %v", text, expected, v)
+		}
+	}
+
+	for _, text := range []string{ "", "ES -", "(ES - NQ", "ES NQ", "2 * 3" } {
+		if _, err := Parse(text); err == nil {
+			t.Errorf("Expression '%v' should not be valid", text)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestCombine(t *testing.T) {
+	base := time.Date(2024,1,2,10,0,0,0, time.UTC)
+	bar  := func(min int, price float64) *ds.DataPoint {
+		return &ds.DataPoint{ Time: base.Add(time.Duration(min)*time.Minute), Open:price, High:price+1, Low:price-1, Close:price }
+	}
+
+	legs := map[string][]*ds.DataPoint{
+		"GC": { bar(0, 20), bar(1, 22), bar(3, 24) },
+		"SI": { bar(1, 10), bar(2, 12), bar(3, 11) },
+	}
+
+	e,_ := Parse("GC / SI")
+	res := Combine(e, legs)
+
+	if len(res) != 2 {
+		t.Fatalf("Expected 2 aligned bars but got %v", len(res))
+	}
+
+	if !res[0].Time.Equal(base.Add(time.Minute)) || res[0].Close != 2.2 {
+		t.Errorf("Unexpected first bar: %v", res[0])
+	}
+
+	if res[1].High != 24.0/11.0 || res[1].Low != 24.0/11.0 {
+		t.Errorf("High/Low must be taken from open and close: %v", res[1])
+	}
+}
+
+//=============================================================================
+
+func TestSymbolsAreSorted(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		e,_ := Parse("ZN + NQ - 2*ES + CL")
+
+		if s := e.Symbols(); !slices.Equal(s, []string{ "CL", "ES", "NQ", "ZN" }) {
+			t.Fatalf("Expected sorted symbols but got %v", s)
+		}
+	}
+}
+
+//=============================================================================
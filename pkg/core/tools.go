@@ -25,6 +25,7 @@ THE SOFTWARE.
 package core
 
 import (
+	"sort"
 	"strings"
 )
 
@@ -99,3 +100,28 @@ func Trunc2d(value float64) float64 {
 }
 
 //=============================================================================
+
+//=============================================================================
+//===
+//=== Statistics
+//===
+//=============================================================================
+
+func Median(values []float64) float64 {
+	n := len(values)
+	if n == 0 {
+		return 0
+	}
+
+	sorted := make([]float64, n)
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	if n % 2 == 1 {
+		return sorted[n/2]
+	}
+
+	return (sorted[n/2 -1] + sorted[n/2]) / 2
+}
+
+//=============================================================================
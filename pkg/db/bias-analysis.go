@@ -63,6 +63,23 @@ func GetBiasAnalysesFull(tx *gorm.DB, filter map[string]any, offset int, limit i
 
 //=============================================================================
 
+func GetBiasAnalysesByInstrumentId(tx *gorm.DB, diId uint) (*[]BiasAnalysis, error) {
+	var list []BiasAnalysis
+
+	filter := map[string]any{}
+	filter["data_instrument_id"] = diId
+
+	res := tx.Where(filter).Find(&list)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+
 func GetBiasAnalysisById(tx *gorm.DB, id uint) (*BiasAnalysis, error) {
 	var list []BiasAnalysis
 	res := tx.Find(&list, id)
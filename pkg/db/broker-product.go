@@ -48,6 +48,19 @@ func GetBrokerProductById(tx *gorm.DB, id uint) (*BrokerProduct, error) {
 
 //=============================================================================
 
+func GetBrokerProductsBySymbol(tx *gorm.DB, username, symbol string) (*[]BrokerProduct, error) {
+	var list []BrokerProduct
+	res := tx.Where("username = ? AND symbol = ?", username, symbol).Find(&list)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+
 func AddBrokerProduct(tx *gorm.DB, p *BrokerProduct) error {
 	return tx.Create(p).Error
 }
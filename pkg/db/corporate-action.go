@@ -0,0 +1,103 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package db
+
+import (
+	"time"
+
+	"github.com/bit-fever/core/req"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func GetCorporateActionsByInstrumentId(tx *gorm.DB, diId uint) (*[]CorporateAction, error) {
+	var list []CorporateAction
+
+	filter := map[string]any{}
+	filter["data_instrument_id"] = diId
+
+	res := tx.Where(filter).Order("ex_date").Find(&list)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+
+func GetCorporateActionById(tx *gorm.DB, id uint) (*CorporateAction, error) {
+	var list []CorporateAction
+	res := tx.Find(&list, id)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	if len(list) == 1 {
+		return &list[0], nil
+	}
+
+	return nil, nil
+}
+
+//=============================================================================
+
+func GetCorporateActionByKey(tx *gorm.DB, diId uint, exDate time.Time, caType CAType) (*CorporateAction, error) {
+	var list []CorporateAction
+	res := tx.Where("data_instrument_id = ? AND ex_date = ? AND type = ?", diId, exDate, caType).Find(&list)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	if len(list) >= 1 {
+		return &list[0], nil
+	}
+
+	return nil, nil
+}
+
+//=============================================================================
+
+func AddCorporateAction(tx *gorm.DB, ca *CorporateAction) error {
+	return tx.Create(ca).Error
+}
+
+//=============================================================================
+
+func UpdateCorporateAction(tx *gorm.DB, ca *CorporateAction) error {
+	return tx.Save(ca).Error
+}
+
+//=============================================================================
+
+func DeleteCorporateAction(tx *gorm.DB, id uint) error {
+	return tx.Delete(&CorporateAction{}, id).Error
+}
+
+//=============================================================================
@@ -25,6 +25,7 @@ THE SOFTWARE.
 package db
 
 import (
+	"github.com/bit-fever/core/datatype"
 	"github.com/bit-fever/core/req"
 	"gorm.io/gorm"
 )
@@ -98,3 +99,27 @@ func UpdateDataBlock(tx *gorm.DB, db *DataBlock) error {
 }
 
 //=============================================================================
+//--- Returns the most recent data date of the blocks used by the instruments
+
+func GetLatestDataToByInstrumentIds(tx *gorm.DB, ids []uint) (datatype.IntDate, error) {
+	var list []datatype.IntDate
+
+	res := tx.
+			Table("data_instrument").
+			Select("COALESCE(MAX(db.data_to), 0)").
+			Joins("JOIN data_block db ON db.id = data_block_id").
+			Where("data_instrument.id IN ?", ids).
+			Find(&list)
+
+	if res.Error != nil {
+		return 0, req.NewServerErrorByError(res.Error)
+	}
+
+	if len(list) == 1 {
+		return list[0], nil
+	}
+
+	return 0, nil
+}
+
+//=============================================================================
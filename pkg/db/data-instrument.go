@@ -40,7 +40,7 @@ func GetDataInstrumentsByProductIdFull(tx *gorm.DB, pId uint, stored bool) (*[]D
 	filter := fmt.Sprintf("data_product_id = %d", pId)
 
 	if stored {
-		filter = filter +" AND (db.status IS NOT NULL OR virtual_instrument = 1)"
+		filter = filter +" AND (db.status IS NOT NULL OR virtual_instrument = 1 OR synthetic = 1)"
 	}
 
 	res := tx.
@@ -160,6 +160,7 @@ func GetDataInstrumentById(tx *gorm.DB, id uint) (*DataInstrument, error) {
 }
 
 //=============================================================================
+//--- Returns the default virtual instrument (the one that follows the product's months)
 
 func GetVirtualDataInstrumentByProductId(tx *gorm.DB, pId uint) (*DataInstrument, error) {
 	var list []DataInstrument
@@ -167,6 +168,7 @@ func GetVirtualDataInstrumentByProductId(tx *gorm.DB, pId uint) (*DataInstrument
 	filter := map[string]any{}
 	filter["data_product_id"]    = pId
 	filter["virtual_instrument"] = true
+	filter["virtual_months"]     = ""
 
 	res := tx.Where(filter).Find(&list)
 
@@ -183,6 +185,42 @@ func GetVirtualDataInstrumentByProductId(tx *gorm.DB, pId uint) (*DataInstrument
 
 //=============================================================================
 
+func GetVirtualDataInstrumentsByProductId(tx *gorm.DB, pId uint) (*[]DataInstrument, error) {
+	var list []DataInstrument
+
+	filter := map[string]any{}
+	filter["data_product_id"]    = pId
+	filter["virtual_instrument"] = true
+
+	res := tx.Where(filter).Order("id").Find(&list)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+
+func GetUserDataInstrumentsBySymbol(tx *gorm.DB, username string, symbol string) (*[]DataInstrument, error) {
+	var list []DataInstrument
+
+	res := tx.
+		Select("data_instrument.*").
+		Joins("JOIN data_product dp ON dp.id = data_product_id").
+		Where("dp.username = ? AND data_instrument.symbol = ?", username, symbol).
+		Find(&list)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+
 func GetDataInstrumentBySymbol(tx *gorm.DB, productId uint, symbol string) (*DataInstrument, error) {
 	filter := map[string]any{}
 	filter["data_product_id"] = productId
@@ -215,3 +253,9 @@ func UpdateDataInstrument(tx *gorm.DB, i *DataInstrument) error {
 }
 
 //=============================================================================
+
+func DeleteDataInstrument(tx *gorm.DB, id uint) error {
+	return tx.Delete(&DataInstrument{}, id).Error
+}
+
+//=============================================================================
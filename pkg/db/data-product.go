@@ -61,6 +61,19 @@ func GetDataProductById(tx *gorm.DB, id uint) (*DataProduct, error) {
 
 //=============================================================================
 
+func GetDataProductsWithoutCurrency(tx *gorm.DB, username, symbol string) (*[]DataProduct, error) {
+	var list []DataProduct
+	res := tx.Where("username = ? AND symbol = ? AND currency_code = ''", username, symbol).Find(&list)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+
 func AddDataProduct(tx *gorm.DB, p *DataProduct) error {
 	return tx.Create(p).Error
 }
@@ -83,6 +96,28 @@ func SetConnectionStatus(tx *gorm.DB, user, code string, flag bool) error {
 
 //=============================================================================
 
+func UpdateDataProductRollSettings(tx *gorm.DB, id uint, estimator DPRollEstimator, timeframe string) error {
+	fields := map[string]interface{}{
+		"roll_estimator" : estimator,
+		"roll_timeframe" : timeframe,
+	}
+
+	return tx.Model(&DataProduct{}).
+		Where("id = ?", id).
+		Updates(fields).
+		Error
+}
+
+//=============================================================================
+
+func UpdateDataProductCurrency(tx *gorm.DB, id uint, currency string) error {
+	return tx.Model(&DataProduct{}).
+		Where("id = ?", id).
+		Update("currency_code", currency).Error
+}
+
+//=============================================================================
+
 func UpdateDataProductFields(tx *gorm.DB, id uint, status DPStatus) error {
 	fields := map[string]interface{}{
 		"status" : status,
@@ -0,0 +1,96 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package db
+
+import (
+	"github.com/bit-fever/core/req"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func GetFxInstruments(tx *gorm.DB, filter map[string]any) (*[]FxInstrument, error) {
+	var list []FxInstrument
+	res := tx.Where(filter).Order("base_currency, quote_currency").Find(&list)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+
+func GetFxInstrumentById(tx *gorm.DB, id uint) (*FxInstrument, error) {
+	var list []FxInstrument
+	res := tx.Find(&list, id)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	if len(list) == 1 {
+		return &list[0], nil
+	}
+
+	return nil, nil
+}
+
+//=============================================================================
+
+func GetFxInstrumentByPair(tx *gorm.DB, username, base, quote string) (*FxInstrument, error) {
+	filter := map[string]any{}
+	filter["username"]       = username
+	filter["base_currency"]  = base
+	filter["quote_currency"] = quote
+
+	var list []FxInstrument
+	res := tx.Where(filter).Find(&list)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	if len(list) == 1 {
+		return &list[0], nil
+	}
+
+	return nil, nil
+}
+
+//=============================================================================
+
+func AddFxInstrument(tx *gorm.DB, fi *FxInstrument) error {
+	return tx.Create(fi).Error
+}
+
+//=============================================================================
+
+func DeleteFxInstrument(tx *gorm.DB, id uint) error {
+	return tx.Delete(&FxInstrument{}, id).Error
+}
+
+//=============================================================================
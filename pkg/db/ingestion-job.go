@@ -24,7 +24,10 @@ THE SOFTWARE.
 
 package db
 
-import "gorm.io/gorm"
+import (
+	"github.com/bit-fever/core/req"
+	"gorm.io/gorm"
+)
 
 //=============================================================================
 
@@ -39,3 +42,22 @@ func UpdateIngestionJob(tx *gorm.DB, job *IngestionJob) error {
 }
 
 //=============================================================================
+//--- User data changes only through uploads, so the last job tells the data version
+
+func GetLatestIngestionJobIdByInstrumentIds(tx *gorm.DB, ids []uint) (uint, error) {
+	var list []uint
+
+	res := tx.Model(&IngestionJob{}).Select("COALESCE(MAX(id), 0)").Where("data_instrument_id IN ?", ids).Find(&list)
+
+	if res.Error != nil {
+		return 0, req.NewServerErrorByError(res.Error)
+	}
+
+	if len(list) == 1 {
+		return list[0], nil
+	}
+
+	return 0, nil
+}
+
+//=============================================================================
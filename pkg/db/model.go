@@ -57,25 +57,39 @@ const (
 type DPRollTrigger string
 
 const (
-	DPRollTriggerSD4  = "sd4"
-	DPRollTriggerSD6  = "sd6"
-	DPRollTriggerSD30 = "sd30"
+	DPRollTriggerSD4    = "sd4"
+	DPRollTriggerSD6    = "sd6"
+	DPRollTriggerSD30   = "sd30"
+	DPRollTriggerVolume = "volume"
+)
+
+//-----------------------------------------------------------------------------
+
+type DPRollEstimator string
+
+const (
+	DPRollEstimatorFirst  = "first"
+	DPRollEstimatorMedian = "median"
+	DPRollEstimatorVWMean = "vwmean"
 )
 
 //-----------------------------------------------------------------------------
 
 type DataProduct struct {
-	Id                   uint          `json:"id" gorm:"primaryKey"`
-	Username             string        `json:"username"`
-	ConnectionCode       string        `json:"connectionCode"`
-	SystemCode           string        `json:"systemCode"`
-	Symbol               string        `json:"symbol"`
-	SupportsMultipleData bool          `json:"supportsMultipleData"`
-	Connected            bool          `json:"connected"`
-	Timezone             string        `json:"timezone"`
-	Status               DPStatus      `json:"status"`
-	Months               string        `json:"months"`
-	RolloverTrigger      DPRollTrigger `json:"rollTrigger"`
+	Id                   uint            `json:"id" gorm:"primaryKey"`
+	Username             string          `json:"username"`
+	ConnectionCode       string          `json:"connectionCode"`
+	SystemCode           string          `json:"systemCode"`
+	Symbol               string          `json:"symbol"`
+	SupportsMultipleData bool            `json:"supportsMultipleData"`
+	Connected            bool            `json:"connected"`
+	Timezone             string          `json:"timezone"`
+	Status               DPStatus        `json:"status"`
+	Months               string          `json:"months"`
+	RolloverTrigger      DPRollTrigger   `json:"rollTrigger"`
+	RollEstimator        DPRollEstimator `json:"rollEstimator"`
+	RollTimeframe        string          `json:"rollTimeframe"`
+	CurrencyCode         string          `json:"currencyCode"`
 }
 
 //=============================================================================
@@ -83,27 +97,34 @@ type DataProduct struct {
 type DIRollStatus int
 
 const (
-	DIRollStatusWaiting DIRollStatus =  0
-	DIRollStatusReady   DIRollStatus =  1
-	DIRollStatusNoMatch DIRollStatus = -1
-	DIRollStatusNoData  DIRollStatus = -2
+	DIRollStatusWaiting       DIRollStatus =  0
+	DIRollStatusReady         DIRollStatus =  1
+	DIRollStatusLowConfidence DIRollStatus =  2
+	DIRollStatusNoMatch       DIRollStatus = -1
+	DIRollStatusNoData        DIRollStatus = -2
 )
 
 //-----------------------------------------------------------------------------
 
 type DataInstrument struct {
-	Id                uint         `json:"id" gorm:"primaryKey"`
-	DataProductId     uint         `json:"dataProductId"`
-	DataBlockId      *uint         `json:"dataBlockId"`
-	Symbol            string       `json:"symbol"`
-	Name              string       `json:"name"`
-	ExpirationDate   *time.Time    `json:"expirationDate,omitempty"`
-	RolloverDate     *time.Time    `json:"rolloverDate,omitempty"`
-	Continuous        bool         `json:"continuous"`
-	Month             string       `json:"month"`
-	RolloverDelta     float64      `json:"rolloverDelta"`
-	RolloverStatus    DIRollStatus `json:"rolloverStatus"`
-	VirtualInstrument bool         `json:"virtualInstrument"`
+	Id                 uint          `json:"id" gorm:"primaryKey"`
+	DataProductId      uint          `json:"dataProductId"`
+	DataBlockId       *uint          `json:"dataBlockId"`
+	Symbol             string        `json:"symbol"`
+	Name               string        `json:"name"`
+	ExpirationDate    *time.Time     `json:"expirationDate,omitempty"`
+	RolloverDate      *time.Time     `json:"rolloverDate,omitempty"`
+	Continuous         bool          `json:"continuous"`
+	Month              string        `json:"month"`
+	RolloverDelta      float64       `json:"rolloverDelta"`
+	RolloverStatus     DIRollStatus  `json:"rolloverStatus"`
+	RolloverDispersion float64       `json:"rolloverDispersion"`
+	RolloverManual     bool          `json:"rolloverManual"`
+	VirtualInstrument  bool          `json:"virtualInstrument"`
+	VirtualMonths      string        `json:"virtualMonths"`
+	VirtualRollTrigger DPRollTrigger `json:"virtualRollTrigger"`
+	Synthetic          bool          `json:"synthetic"`
+	Expression         string        `json:"expression"`
 }
 
 //=============================================================================
@@ -141,6 +162,96 @@ type DataInstrumentExt struct {
 
 //=============================================================================
 
+type RollOverride struct {
+	Id                uint      `json:"id" gorm:"primaryKey"`
+	DataProductId     uint      `json:"dataProductId"`
+	DataInstrumentId  uint      `json:"dataInstrumentId"`
+	RolloverDate      time.Time `json:"rolloverDate"`
+	Notes             string    `json:"notes"`
+}
+
+//=============================================================================
+
+type RollOverrideFull struct {
+	RollOverride
+	Symbol         string     `json:"symbol"`
+	ExpirationDate *time.Time `json:"expirationDate,omitempty"`
+}
+
+//=============================================================================
+//--- Rollover data of a virtual instrument with its own month set or roll trigger.
+//--- The default virtual instrument uses the fields of DataInstrument instead
+
+type VirtualRollover struct {
+	Id                  uint         `json:"id" gorm:"primaryKey"`
+	VirtualInstrumentId uint         `json:"virtualInstrumentId"`
+	DataInstrumentId    uint         `json:"dataInstrumentId"`
+	RolloverDate       *time.Time    `json:"rolloverDate,omitempty"`
+	RolloverDelta       float64      `json:"rolloverDelta"`
+	RolloverStatus      DIRollStatus `json:"rolloverStatus"`
+	RolloverDispersion  float64      `json:"rolloverDispersion"`
+}
+
+//=============================================================================
+
+type RHTrigger string
+
+const (
+	RHTriggerInventory = "inventory"
+	RHTriggerDownload  = "download"
+	RHTriggerOverride  = "override"
+	RHTriggerManual    = "manual"
+	RHTriggerUnpin     = "unpin"
+	RHTriggerSettings  = "settings"
+)
+
+//-----------------------------------------------------------------------------
+
+type RolloverHistory struct {
+	Id                  uint         `json:"id" gorm:"primaryKey"`
+	CreatedAt           time.Time    `json:"createdAt"`
+	DataProductId       uint         `json:"dataProductId"`
+	DataInstrumentId    uint         `json:"dataInstrumentId"`
+	VirtualInstrumentId uint         `json:"virtualInstrumentId"`
+	Trigger             RHTrigger    `json:"trigger"`
+	Username            string       `json:"username"`
+	OldDate            *time.Time    `json:"oldDate,omitempty"`
+	OldDelta            float64      `json:"oldDelta"`
+	OldStatus           DIRollStatus `json:"oldStatus"`
+	NewDate            *time.Time    `json:"newDate,omitempty"`
+	NewDelta            float64      `json:"newDelta"`
+	NewStatus           DIRollStatus `json:"newStatus"`
+	NextSymbol          string       `json:"nextSymbol"`
+	MatchedBars         int          `json:"matchedBars"`
+	Dispersion          float64      `json:"dispersion"`
+	CurrPrice           float64      `json:"currPrice"`
+	NextPrice           float64      `json:"nextPrice"`
+}
+
+//=============================================================================
+
+type CAType string
+
+const (
+	CATypeSplit    = "split"
+	CATypeDividend = "dividend"
+)
+
+//-----------------------------------------------------------------------------
+//--- For splits, value is the number of new shares for each old one (i.e. 2 for
+//--- a 2:1 split). For dividends, it is the cash amount per share
+
+type CorporateAction struct {
+	Id               uint      `json:"id" gorm:"primaryKey"`
+	DataInstrumentId uint      `json:"dataInstrumentId"`
+	ExDate           time.Time `json:"exDate"`
+	Type             CAType    `json:"type"`
+	Value            float64   `json:"value"`
+	Notes            string    `json:"notes"`
+}
+
+//=============================================================================
+
 type DBStatus int
 
 const (
@@ -223,6 +334,23 @@ type BrokerProduct struct {
 	PointValue       float32 `json:"pointValue"`
 	CostPerOperation float32 `json:"costPerOperation"`
 	CurrencyCode     string  `json:"currencyCode"`
+	TickSize         float32 `json:"tickSize"`
+}
+
+//=============================================================================
+//===
+//=== Currency entities
+//===
+//=============================================================================
+//--- The data instrument holds the price of 1 unit of base currency expressed
+//--- in the quote currency (i.e. EUR/USD = 1.08)
+
+type FxInstrument struct {
+	Id               uint    `json:"id" gorm:"primaryKey"`
+	Username         string  `json:"username"`
+	BaseCurrency     string  `json:"baseCurrency"`
+	QuoteCurrency    string  `json:"quoteCurrency"`
+	DataInstrumentId uint    `json:"dataInstrumentId"`
 }
 
 //=============================================================================
@@ -238,6 +366,7 @@ type BiasAnalysis struct {
 	BrokerProductId   uint    `json:"brokerProductId"`
 	Name              string  `json:"name"`
 	Notes             string  `json:"notes"`
+	SlotSize          int16   `json:"slotSize"`
 }
 
 //=============================================================================
@@ -272,13 +401,18 @@ type BiasConfig struct {
 //===
 //=============================================================================
 
-func (DataProduct)    TableName() string { return "data_product"    }
-func (DataInstrument) TableName() string { return "data_instrument" }
-func (DataBlock)      TableName() string { return "data_block"      }
-func (BrokerProduct)  TableName() string { return "broker_product"  }
-func (IngestionJob)   TableName() string { return "ingestion_job"   }
-func (DownloadJob)    TableName() string { return "download_job"    }
-func (BiasAnalysis)   TableName() string { return "bias_analysis"   }
-func (BiasConfig)     TableName() string { return "bias_config"     }
+func (DataProduct)     TableName() string { return "data_product"     }
+func (DataInstrument)  TableName() string { return "data_instrument"  }
+func (RollOverride)    TableName() string { return "roll_override"    }
+func (RolloverHistory) TableName() string { return "rollover_history" }
+func (VirtualRollover) TableName() string { return "virtual_rollover" }
+func (CorporateAction) TableName() string { return "corporate_action" }
+func (DataBlock)       TableName() string { return "data_block"       }
+func (BrokerProduct)   TableName() string { return "broker_product"   }
+func (FxInstrument)    TableName() string { return "fx_instrument"    }
+func (IngestionJob)    TableName() string { return "ingestion_job"    }
+func (DownloadJob)     TableName() string { return "download_job"     }
+func (BiasAnalysis)    TableName() string { return "bias_analysis"    }
+func (BiasConfig)      TableName() string { return "bias_config"      }
 
 //=============================================================================
@@ -0,0 +1,110 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package db
+
+import (
+	"github.com/bit-fever/core/req"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func GetRollOverridesByProductId(tx *gorm.DB, pId uint) (*[]RollOverrideFull, error) {
+	var list []RollOverrideFull
+
+	filter := map[string]any{}
+	filter["roll_override.data_product_id"] = pId
+
+	res := tx.
+		Select("roll_override.*, di.symbol, di.expiration_date").
+		Joins("JOIN data_instrument di ON di.id = data_instrument_id").
+		Where(filter).
+		Order("rollover_date").
+		Find(&list)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+
+func GetRollOverrideById(tx *gorm.DB, id uint) (*RollOverride, error) {
+	var list []RollOverride
+	res := tx.Find(&list, id)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	if len(list) == 1 {
+		return &list[0], nil
+	}
+
+	return nil, nil
+}
+
+//=============================================================================
+
+func GetRollOverrideByInstrumentId(tx *gorm.DB, diId uint) (*RollOverride, error) {
+	var list []RollOverride
+
+	filter := map[string]any{}
+	filter["data_instrument_id"] = diId
+
+	res := tx.Where(filter).Find(&list)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	if len(list) == 1 {
+		return &list[0], nil
+	}
+
+	return nil, nil
+}
+
+//=============================================================================
+
+func AddRollOverride(tx *gorm.DB, ro *RollOverride) error {
+	return tx.Create(ro).Error
+}
+
+//=============================================================================
+
+func UpdateRollOverride(tx *gorm.DB, ro *RollOverride) error {
+	return tx.Save(ro).Error
+}
+
+//=============================================================================
+
+func DeleteRollOverride(tx *gorm.DB, id uint) error {
+	return tx.Delete(&RollOverride{}, id).Error
+}
+
+//=============================================================================
@@ -107,6 +107,13 @@ func (a *DataAggregator) Aggregate(daDes *DataAggregator) {
 	daDes.Flush()
 }
 
+//=============================================================================
+//--- Returns an empty aggregator with the same time slot function and location
+
+func (a *DataAggregator) NewEmptyCopy() *DataAggregator {
+	return NewDataAggregator(a.timeSlotFunc, a.productLoc)
+}
+
 //=============================================================================
 
 func (a *DataAggregator) Clear() {
@@ -226,6 +233,16 @@ func TimeSlotFunction60m(dpTime time.Time) time.Time {
 
 //=============================================================================
 
+func TimeSlotFunction120m(dpTime time.Time) time.Time {
+	hours,mins,_ := dpTime.Clock()
+
+	if mins ==  0 && hours % 2 == 0 { return dpTime }
+
+	return dpTime.Add(time.Minute * time.Duration(120-mins-(hours % 2)*60))
+}
+
+//=============================================================================
+
 func TimeSlotFunction1440m(dpTime time.Time) time.Time {
 	hours,mins,_ := dpTime.Clock()
 
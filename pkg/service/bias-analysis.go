@@ -258,17 +258,52 @@ func getBiasSummary(c *auth.Context) {
 	id, err := c.GetIdFromUrl()
 
 	if err == nil {
+		var compact bool
+		compact, err = c.GetParamAsBool("compact", false)
+
 		var bsr *business.BiasSummaryResponse
 
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				bsr, err = business.GetBiasSummaryInfo(tx, c, id)
+				return err
+			})
+		}
+
+		if err == nil {
+			err = business.GetBiasSummaryData(c, id, bsr, compact)
+			if err == nil {
+				_=c.ReturnObject(bsr)
+				return
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getSeasonality(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		spec := &business.SeasonalitySpec{
+			Excludes: c.GetParamAsString("excludes", ""),
+			Years   : c.GetParamAsString("years",    ""),
+		}
+
+		var sr *business.SeasonalityResponse
+
 		err = db.RunInTransaction(func(tx *gorm.DB) error {
-			bsr, err = business.GetBiasSummaryInfo(tx, c, id)
+			sr, err = business.GetSeasonalityInfo(tx, c, id, spec)
 			return err
 		})
 
 		if err == nil {
-			err = business.GetBiasSummaryData(c, id, bsr)
+			err = business.GetSeasonalityData(c, id, sr)
 			if err == nil {
-				_=c.ReturnObject(bsr)
+				_=c.ReturnObject(sr)
 				return
 			}
 		}
@@ -310,3 +345,140 @@ func runBacktest(c *auth.Context) {
 }
 
 //=============================================================================
+
+func runWalkForward(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var wfs business.WalkForwardSpec
+		err = c.BindParamsFromBody(&wfs)
+
+		if err == nil {
+			var wfr *business.WalkForwardResponse
+
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				wfr, err = business.GetWalkForwardInfo(tx, c, id, &wfs)
+				return err
+			})
+
+			if err == nil {
+				err = business.RunWalkForward(c, wfr)
+				if err == nil {
+					_=c.ReturnObject(wfr)
+					return
+				}
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func runOptimization(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var os business.OptimizationSpec
+		err = c.BindParamsFromBody(&os)
+
+		if err == nil {
+			var opr *business.OptimizationResponse
+
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				opr, err = business.GetOptimizationInfo(tx, c, id, &os)
+				return err
+			})
+
+			if err == nil {
+				err = business.RunOptimization(c, opr)
+				if err == nil {
+					_=c.ReturnObject(opr)
+					return
+				}
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+//=== Scans
+//=============================================================================
+
+func startBiasScan(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var bss business.BiasScanSpec
+		err = c.BindParamsFromBody(&bss)
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				job, err := business.StartBiasScan(tx, c, id, &bss)
+
+				if err != nil {
+					return err
+				}
+
+				return c.ReturnObject(job)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getBiasScanJob(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var jobId uint
+		jobId, err = c.GetId2FromUrl()
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				job, err := business.GetBiasScanJob(tx, c, id, jobId)
+
+				if err != nil {
+					return err
+				}
+
+				return c.ReturnObject(job)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func deleteBiasScanJob(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var jobId uint
+		jobId, err = c.GetId2FromUrl()
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				job, err := business.DeleteBiasScanJob(tx, c, id, jobId)
+
+				if err != nil {
+					return err
+				}
+
+				return c.ReturnObject(job)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
@@ -0,0 +1,154 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package service
+
+import (
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/data-collector/pkg/business"
+	"github.com/bit-fever/data-collector/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func getCorporateActions(c *auth.Context) {
+	diId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			list, err := business.GetCorporateActions(tx, c, diId)
+
+			if err != nil {
+				return err
+			}
+
+			return c.ReturnList(list, 0, len(*list), len(*list))
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func addCorporateAction(c *auth.Context) {
+	diId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var spec business.CorporateActionSpec
+		err = c.BindParamsFromBody(&spec)
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				ca, err := business.AddCorporateAction(tx, c, diId, &spec)
+
+				if err != nil {
+					return err
+				}
+
+				return c.ReturnObject(ca)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func updateCorporateAction(c *auth.Context) {
+	diId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var caId uint
+		caId, err = c.GetId2FromUrl()
+
+		if err == nil {
+			var spec business.CorporateActionSpec
+			err = c.BindParamsFromBody(&spec)
+
+			if err == nil {
+				err = db.RunInTransaction(func(tx *gorm.DB) error {
+					ca, err := business.UpdateCorporateAction(tx, c, diId, caId, &spec)
+
+					if err != nil {
+						return err
+					}
+
+					return c.ReturnObject(ca)
+				})
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func deleteCorporateAction(c *auth.Context) {
+	diId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var caId uint
+		caId, err = c.GetId2FromUrl()
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				ca, err := business.DeleteCorporateAction(tx, c, diId, caId)
+
+				if err != nil {
+					return err
+				}
+
+				return c.ReturnObject(ca)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func uploadCorporateActions(c *auth.Context) {
+	diId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			res, err := business.UploadCorporateActions(tx, c, diId, c.Gin.Request.Body)
+
+			if err != nil {
+				return err
+			}
+
+			return c.ReturnObject(res)
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
@@ -82,10 +82,18 @@ func getDataInstrumentData(c *auth.Context) {
 
 	id, err   := c.GetIdFromUrl()
 	timeframe := c.GetParamAsString("timeframe",  "5m")
+	currency  := c.GetParamAsString("currency",   "")
+	adjust    := c.GetParamAsString("adjustment", "")
 
 	if err == nil {
 		err = db.RunInTransaction(func(tx *gorm.DB) error {
 			cfg, err := business.CreateDataConfig(tx, id)
+			if err == nil {
+				err = business.SetCurrencyConversion(tx, c, cfg, currency)
+				if err == nil {
+					err = business.SetPriceAdjustment(tx, c, cfg, id, adjust)
+				}
+			}
 			config = cfg
 			return err
 		})
@@ -93,12 +101,14 @@ func getDataInstrumentData(c *auth.Context) {
 		if err == nil {
 			config.DataConfig.Timeframe = timeframe
 			spec := &business.DataInstrumentDataSpec{
-				Id       : id,
-				From     : c.GetParamAsString("from",     ""),
-				To       : c.GetParamAsString("to",       ""),
-				Timezone : c.GetParamAsString("timezone", "UTC"),
-				Reduction: c.GetParamAsString("reduction",""),
-				Config   : config,
+				Id        : id,
+				From      : c.GetParamAsString("from",     ""),
+				To        : c.GetParamAsString("to",       ""),
+				Timezone  : c.GetParamAsString("timezone", "UTC"),
+				Reduction : c.GetParamAsString("reduction",""),
+				Algorithm : c.GetParamAsString("algorithm",""),
+				Indicators: c.GetParamAsString("indicators",""),
+				Config    : config,
 			}
 			result, err = business.GetDataInstrumentDataById(c, spec)
 			if err == nil {
@@ -113,6 +123,41 @@ func getDataInstrumentData(c *auth.Context) {
 
 //=============================================================================
 
+func getDataInstrumentStatistics(c *auth.Context) {
+	var config *business.DataConfig
+
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			config, err = business.GetInstrumentStatisticsConfig(tx, c, id)
+			return err
+		})
+
+		if err == nil {
+			config.DataConfig.Timeframe = c.GetParamAsString("timeframe", "30m")
+			spec := &business.DataInstrumentDataSpec{
+				Id      : id,
+				From    : c.GetParamAsString("from",     ""),
+				To      : c.GetParamAsString("to",       ""),
+				Timezone: c.GetParamAsString("timezone", "exchange"),
+				Config  : config,
+			}
+
+			var result *business.InstrumentStatisticsResponse
+			result, err = business.GetInstrumentStatistics(c, spec)
+			if err == nil {
+				_=c.ReturnObject(result)
+				return
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
 func reloadDataInstrumentData(c *auth.Context) {
 	id,err := c.GetIdFromUrl()
 
@@ -134,3 +179,55 @@ func reloadDataInstrumentData(c *auth.Context) {
 }
 
 //=============================================================================
+
+func getRolloverHistory(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			list, err := business.GetRolloverHistory(tx, c, id)
+
+			if err != nil {
+				return err
+			}
+
+			return c.ReturnList(list, 0, len(*list), len(*list))
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func setManualRollover(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var spec business.ManualRolloverSpec
+		err = c.BindParamsFromBody(&spec)
+
+		if err == nil {
+			var di *db.DataInstrument
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				di, err = business.SetManualRollover(tx, c, id, &spec)
+				return err
+			})
+
+			if err == nil {
+				if !spec.Manual {
+					err = business.SendRollRecalcMessage(c, di.DataProductId, db.RHTriggerUnpin, true)
+				}
+
+				if err == nil {
+					_ = c.ReturnObject(di)
+					return
+				}
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
@@ -109,6 +109,257 @@ func uploadDataInstrumentData(c *auth.Context) {
 	c.ReturnError(err)
 }
 
+//=============================================================================
+//=== Roll overrides
+//=============================================================================
+
+func getRollOverrides(c *auth.Context) {
+	pId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			list, err := business.GetRollOverridesByProductId(tx, c, pId)
+
+			if err != nil {
+				return err
+			}
+
+			return c.ReturnList(list, 0, len(*list), len(*list))
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func addRollOverride(c *auth.Context) {
+	pId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var spec business.RollOverrideSpec
+		err = c.BindParamsFromBody(&spec)
+
+		if err == nil {
+			var ro *db.RollOverride
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				ro, err = business.AddRollOverride(tx, c, pId, &spec)
+				return err
+			})
+
+			if err == nil {
+				err = business.SendRollRecalcMessage(c, pId, db.RHTriggerOverride, true)
+				if err == nil {
+					_ = c.ReturnObject(ro)
+					return
+				}
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func updateRollOverride(c *auth.Context) {
+	pId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var roId uint
+		roId, err = c.GetId2FromUrl()
+
+		if err == nil {
+			var spec business.RollOverrideSpec
+			err = c.BindParamsFromBody(&spec)
+
+			if err == nil {
+				var ro *db.RollOverride
+				err = db.RunInTransaction(func(tx *gorm.DB) error {
+					ro, err = business.UpdateRollOverride(tx, c, pId, roId, &spec)
+					return err
+				})
+
+				if err == nil {
+					err = business.SendRollRecalcMessage(c, pId, db.RHTriggerOverride, true)
+					if err == nil {
+						_ = c.ReturnObject(ro)
+						return
+					}
+				}
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func deleteRollOverride(c *auth.Context) {
+	pId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var roId uint
+		roId, err = c.GetId2FromUrl()
+
+		if err == nil {
+			var ro *db.RollOverride
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				ro, err = business.DeleteRollOverride(tx, c, pId, roId)
+				return err
+			})
+
+			if err == nil {
+				err = business.SendRollRecalcMessage(c, pId, db.RHTriggerOverride, true)
+				if err == nil {
+					_ = c.ReturnObject(ro)
+					return
+				}
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func updateRollSettings(c *auth.Context) {
+	pId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var spec business.RollSettingsSpec
+		err = c.BindParamsFromBody(&spec)
+
+		if err == nil {
+			var dp *db.DataProduct
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				dp, err = business.UpdateRollSettings(tx, c, pId, &spec)
+				return err
+			})
+
+			if err == nil {
+				err = business.SendRollRecalcMessage(c, pId, db.RHTriggerSettings, true)
+				if err == nil {
+					_ = c.ReturnObject(dp)
+					return
+				}
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+//=== Virtual instruments
+//=============================================================================
+
+func addVirtualInstrument(c *auth.Context) {
+	pId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var spec business.VirtualInstrumentSpec
+		err = c.BindParamsFromBody(&spec)
+
+		if err == nil {
+			var di *db.DataInstrument
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				di, err = business.AddVirtualInstrument(tx, c, pId, &spec)
+				return err
+			})
+
+			if err == nil {
+				err = business.SendRollRecalcMessage(c, pId, db.RHTriggerSettings, false)
+				if err == nil {
+					_ = c.ReturnObject(di)
+					return
+				}
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func deleteVirtualInstrument(c *auth.Context) {
+	pId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var viId uint
+		viId, err = c.GetId2FromUrl()
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				di, err := business.DeleteVirtualInstrument(tx, c, pId, viId)
+
+				if err != nil {
+					return err
+				}
+
+				return c.ReturnObject(di)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+//=== Synthetic instruments
+//=============================================================================
+
+func addSyntheticInstrument(c *auth.Context) {
+	pId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var spec business.SyntheticInstrumentSpec
+		err = c.BindParamsFromBody(&spec)
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				di, err := business.AddSyntheticInstrument(tx, c, pId, &spec)
+
+				if err != nil {
+					return err
+				}
+
+				return c.ReturnObject(di)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func deleteSyntheticInstrument(c *auth.Context) {
+	pId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var siId uint
+		siId, err = c.GetId2FromUrl()
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				di, err := business.DeleteSyntheticInstrument(tx, c, pId, siId)
+
+				if err != nil {
+					return err
+				}
+
+				return c.ReturnObject(di)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
 //=============================================================================
 //===
 //=== Private methods
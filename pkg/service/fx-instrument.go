@@ -0,0 +1,91 @@
+//=============================================================================
+/*
+Copyright © 2026 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package service
+
+import (
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/data-collector/pkg/business"
+	"github.com/bit-fever/data-collector/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func getFxInstruments(c *auth.Context) {
+	err := db.RunInTransaction(func(tx *gorm.DB) error {
+		list, err := business.GetFxInstruments(tx, c)
+
+		if err != nil {
+			return err
+		}
+
+		return c.ReturnList(list, 0, len(*list), len(*list))
+	})
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func addFxInstrument(c *auth.Context) {
+	var spec business.FxInstrumentSpec
+	err := c.BindParamsFromBody(&spec)
+
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			fi, err := business.AddFxInstrument(tx, c, &spec)
+
+			if err != nil {
+				return err
+			}
+
+			return c.ReturnObject(fi)
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func deleteFxInstrument(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			fi, err := business.DeleteFxInstrument(tx, c, id)
+
+			if err != nil {
+				return err
+			}
+
+			return c.ReturnObject(fi)
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
@@ -45,18 +45,51 @@ func Init(router *gin.Engine, cfg *app.Config, logger *slog.Logger) {
 	router.GET ("/api/collector/v1/data-instruments",                   ctrl.Secure(getDataInstruments,            roles.Admin_User_Service))
 	router.GET ("/api/collector/v1/data-instruments/:id",               ctrl.Secure(getDataInstrumentById,         roles.Admin_User_Service))
 	router.GET ("/api/collector/v1/data-instruments/:id/data",          ctrl.Secure(getDataInstrumentData,         roles.Admin_User_Service))
+	router.GET ("/api/collector/v1/data-instruments/:id/statistics",    ctrl.Secure(getDataInstrumentStatistics,   roles.Admin_User_Service))
 	router.POST("/api/collector/v1/data-instruments/:id/reload",        ctrl.Secure(reloadDataInstrumentData,      roles.Admin_User_Service))
+	router.PUT ("/api/collector/v1/data-instruments/:id/rollover",      ctrl.Secure(setManualRollover,             roles.Admin_User_Service))
+	router.GET ("/api/collector/v1/data-instruments/:id/rollover-history", ctrl.Secure(getRolloverHistory,       roles.Admin_User_Service))
+
+	router.GET   ("/api/collector/v1/data-instruments/:id/corporate-actions",        ctrl.Secure(getCorporateActions,    roles.Admin_User_Service))
+	router.POST  ("/api/collector/v1/data-instruments/:id/corporate-actions",        ctrl.Secure(addCorporateAction,     roles.Admin_User_Service))
+	router.POST  ("/api/collector/v1/data-instruments/:id/corporate-actions/upload", ctrl.Secure(uploadCorporateActions, roles.Admin_User_Service))
+	router.PUT   ("/api/collector/v1/data-instruments/:id/corporate-actions/:id2",   ctrl.Secure(updateCorporateAction,  roles.Admin_User_Service))
+	router.DELETE("/api/collector/v1/data-instruments/:id/corporate-actions/:id2",   ctrl.Secure(deleteCorporateAction,  roles.Admin_User_Service))
 
 	router.GET ("/api/collector/v1/data-products/:id/instruments",      ctrl.Secure(getDataInstrumentsByProductId, roles.Admin_User_Service))
 	router.POST("/api/collector/v1/data-products/:id/instruments",      ctrl.Secure(uploadDataInstrumentData,      roles.Admin_User_Service))
 
+	router.GET   ("/api/collector/v1/data-products/:id/roll-overrides",      ctrl.Secure(getRollOverrides,      roles.Admin_User_Service))
+	router.POST  ("/api/collector/v1/data-products/:id/roll-overrides",      ctrl.Secure(addRollOverride,       roles.Admin_User_Service))
+	router.PUT   ("/api/collector/v1/data-products/:id/roll-overrides/:id2", ctrl.Secure(updateRollOverride,    roles.Admin_User_Service))
+	router.DELETE("/api/collector/v1/data-products/:id/roll-overrides/:id2", ctrl.Secure(deleteRollOverride,    roles.Admin_User_Service))
+	router.PUT   ("/api/collector/v1/data-products/:id/roll-settings",       ctrl.Secure(updateRollSettings,    roles.Admin_User_Service))
+
+	router.POST  ("/api/collector/v1/data-products/:id/virtual-instruments",      ctrl.Secure(addVirtualInstrument,    roles.Admin_User_Service))
+	router.DELETE("/api/collector/v1/data-products/:id/virtual-instruments/:id2", ctrl.Secure(deleteVirtualInstrument, roles.Admin_User_Service))
+
+	router.POST  ("/api/collector/v1/data-products/:id/synthetic-instruments",      ctrl.Secure(addSyntheticInstrument,    roles.Admin_User_Service))
+	router.DELETE("/api/collector/v1/data-products/:id/synthetic-instruments/:id2", ctrl.Secure(deleteSyntheticInstrument, roles.Admin_User_Service))
+
+	router.GET   ("/api/collector/v1/fx-instruments",                   ctrl.Secure(getFxInstruments,              roles.Admin_User_Service))
+	router.POST  ("/api/collector/v1/fx-instruments",                   ctrl.Secure(addFxInstrument,               roles.Admin_User_Service))
+	router.DELETE("/api/collector/v1/fx-instruments/:id",               ctrl.Secure(deleteFxInstrument,            roles.Admin_User_Service))
+
+	router.GET   ("/api/collector/v1/correlations",                     ctrl.Secure(getCorrelations,               roles.Admin_User_Service))
+
 	router.GET   ("/api/collector/v1/bias-analyses",                    ctrl.Secure(getBiasAnalyses,               roles.Admin_User_Service))
 	router.POST  ("/api/collector/v1/bias-analyses",                    ctrl.Secure(addBiasAnalysis,               roles.Admin_User_Service))
 	router.GET   ("/api/collector/v1/bias-analyses/:id",                ctrl.Secure(getBiasAnalysisById,           roles.Admin_User_Service))
 	router.PUT   ("/api/collector/v1/bias-analyses/:id",                ctrl.Secure(updateBiasAnalysis,            roles.Admin_User_Service))
 	router.DELETE("/api/collector/v1/bias-analyses/:id",                ctrl.Secure(deleteBiasAnalysis,            roles.Admin_User_Service))
 	router.GET   ("/api/collector/v1/bias-analyses/:id/summary",        ctrl.Secure(getBiasSummary,                roles.Admin_User_Service))
+	router.GET   ("/api/collector/v1/bias-analyses/:id/seasonality",    ctrl.Secure(getSeasonality,                roles.Admin_User_Service))
 	router.POST  ("/api/collector/v1/bias-analyses/:id/backtest",       ctrl.Secure(runBacktest,                   roles.Admin_User_Service))
+	router.POST  ("/api/collector/v1/bias-analyses/:id/walk-forward",   ctrl.Secure(runWalkForward,                roles.Admin_User_Service))
+	router.POST  ("/api/collector/v1/bias-analyses/:id/optimization",   ctrl.Secure(runOptimization,               roles.Admin_User_Service))
+	router.POST  ("/api/collector/v1/bias-analyses/:id/scans",          ctrl.Secure(startBiasScan,                 roles.Admin_User_Service))
+	router.GET   ("/api/collector/v1/bias-analyses/:id/scans/:id2",     ctrl.Secure(getBiasScanJob,                roles.Admin_User_Service))
+	router.DELETE("/api/collector/v1/bias-analyses/:id/scans/:id2",     ctrl.Secure(deleteBiasScanJob,             roles.Admin_User_Service))
 
 	router.GET   ("/api/collector/v1/bias-analyses/:id/configs",        ctrl.Secure(getBiasConfigsByAnalysisId,    roles.Admin_User_Service))
 	router.POST  ("/api/collector/v1/bias-analyses/:id/configs",        ctrl.Secure(addBiasConfig,                 roles.Admin_User_Service))